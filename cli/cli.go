@@ -0,0 +1,290 @@
+/*
+ Copyright 2020  l1b0k
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package cli
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"github.com/l1b0k/volans/modle"
+	"github.com/l1b0k/volans/views"
+)
+
+const usage = `usage: volans [flags] <command>
+
+commands:
+  ns list [-type TYPE]                  list namespaces
+  ns show <inode>                       show all details of a namespace
+  net [-section NAME] <inode>           show a section of a net namespace: links, sockets, routes, rules, neigh
+  procs [-tree] [-columns C] <inode>    list processes of a namespace, -tree order them by parent
+  snapshot -o FILE                      save the state of this node as tar.gz, browse it later with -replay FILE
+  diff <old> [new]                      show what changed between two snapshots, or from a snapshot to now
+
+other commands accept -o table|json|yaml, without command the interactive UI is started
+`
+
+// ErrUsage is returned when the command line is not valid
+var ErrUsage = errors.New(usage)
+
+// section is a table of a namespace, titled by name
+type section struct {
+	name string
+	load func(ns string) (table, error)
+}
+
+// typedSection is a section of a Dao method returning a slice of modle types
+func typedSection(name string, fields []views.Field, items func(ns string) interface{}) section {
+	return section{name, func(ns string) (table, error) { return typedTable(fields, items(ns)), nil }}
+}
+
+// netSections is the sections of a net namespace, in the order of the UI
+func netSections(d *modle.Dao) []section {
+	return []section{
+		{"links", func(ns string) (table, error) {
+			ifaces, err := d.GetNetNSDetail(ns)
+			return typedTable(views.NetNSFields(), ifaces), err
+		}},
+		typedSection("sockets", views.SocketFields(), func(ns string) interface{} { return d.GetSocketDetail(ns) }),
+		typedSection("routes", views.RouteFields(), func(ns string) interface{} { return d.GetRouteDetail(ns) }),
+		typedSection("rules", views.RuleFields(), func(ns string) interface{} { return d.GetRuleDetail(ns) }),
+		typedSection("neigh", views.NeighFields(), func(ns string) interface{} { return d.GetNeighDetail(ns) }),
+	}
+}
+
+// detailSections return the sections shown by the detail pane for nsType
+func detailSections(d *modle.Dao, nsType string) []section {
+	switch nsType {
+	case "cgroup":
+		return []section{typedSection("cgroup", views.CgroupFields(), func(ns string) interface{} { return d.GetCgroupDetail(ns) })}
+	case "ipc":
+		return []section{typedSection("ipc", views.IPCFields(), func(ns string) interface{} { return d.GetIPCDetail(ns) })}
+	case "mnt":
+		return []section{typedSection("mounts", views.MntFields(), func(ns string) interface{} { return d.GetMountDetail(ns) })}
+	case "net":
+		return netSections(d)
+	case "user":
+		return []section{typedSection("idmap", views.UserFields(), func(ns string) interface{} { return d.GetUserDetail(ns) })}
+	case "uts":
+		return []section{typedSection("uts", views.UTSFields(), func(ns string) interface{} { return d.GetUTSDetail(ns) })}
+	}
+	return nil
+}
+
+// Run execute the subcommand in args and write the result to out
+func Run(args []string, out io.Writer) error {
+	if len(args) == 0 {
+		return ErrUsage
+	}
+	switch args[0] {
+	case "ns":
+		if len(args) < 2 {
+			return ErrUsage
+		}
+		switch args[1] {
+		case "list":
+			return nsList(args[2:], out)
+		case "show":
+			return nsShow(args[2:], out)
+		}
+	case "net":
+		return net(args[1:], out)
+	case "procs":
+		return procs(args[1:], out)
+	case "snapshot":
+		return snapshot(args[1:], out)
+	case "diff":
+		return diff(args[1:], out)
+	}
+	return ErrUsage
+}
+
+func nsList(args []string, out io.Writer) error {
+	fs := flag.NewFlagSet("ns list", flag.ContinueOnError)
+	format := fs.String("o", "table", "output format: table, json or yaml")
+	nsType := fs.String("type", "", "only list namespaces of this type")
+	if _, err := parseArgs(fs, args, 0); err != nil {
+		return err
+	}
+
+	d := modle.GetDao()
+	var nss []modle.Namespace
+	for _, n := range d.GetNSWithPidCount() {
+		if *nsType != "" && n.Type != *nsType {
+			continue
+		}
+		nss = append(nss, n)
+	}
+	return write(out, *format, typedTable(views.NSFields(), nss))
+}
+
+func nsShow(args []string, out io.Writer) error {
+	fs := flag.NewFlagSet("ns show", flag.ContinueOnError)
+	format := fs.String("o", "table", "output format: table, json or yaml")
+	pos, err := parseArgs(fs, args, 1)
+	if err != nil {
+		return err
+	}
+	ns := pos[0]
+
+	d := modle.GetDao()
+	nsType := d.GetNSType(ns)
+	if nsType == "" {
+		return fmt.Errorf("namespace %s not found", ns)
+	}
+	return writeSections(out, *format, ns, nsType, detailSections(d, nsType))
+}
+
+func net(args []string, out io.Writer) error {
+	fs := flag.NewFlagSet("net", flag.ContinueOnError)
+	format := fs.String("o", "table", "output format: table, json or yaml")
+	name := fs.String("section", "links", "links, sockets, routes, rules or neigh")
+	pos, err := parseArgs(fs, args, 1)
+	if err != nil {
+		return err
+	}
+	ns := pos[0]
+
+	d := modle.GetDao()
+	if nsType := d.GetNSType(ns); nsType != "net" {
+		return fmt.Errorf("namespace %s is not a net namespace", ns)
+	}
+	for _, s := range netSections(d) {
+		if s.name == *name {
+			t, err := s.load(ns)
+			if err != nil {
+				return err
+			}
+			return write(out, *format, t)
+		}
+	}
+	return fmt.Errorf("unknown section %s", *name)
+}
+
+func procs(args []string, out io.Writer) error {
+	fs := flag.NewFlagSet("procs", flag.ContinueOnError)
+	format := fs.String("o", "table", "output format: table, json or yaml")
+	tree := fs.Bool("tree", false, "indent children under their parent in table output")
+	columns := fs.String("columns", "", "comma separated columns of table output, empty for the default ones")
+	pos, err := parseArgs(fs, args, 1)
+	if err != nil {
+		return err
+	}
+	ns := pos[0]
+
+	d := modle.GetDao()
+	if d.GetNSType(ns) == "" {
+		return fmt.Errorf("namespace %s not found", ns)
+	}
+	fields := views.ProcFields()
+	if *columns != "" {
+		if err := views.SelectFields(fields, strings.Split(*columns, ",")); err != nil {
+			return err
+		}
+	}
+	procs := d.GetProcDetail(ns)
+	if !*tree {
+		return write(out, *format, typedTable(fields, procs))
+	}
+	t := typedTable(fields, views.ProcTree(procs, nil))
+	t.value = procs
+	return write(out, *format, t)
+}
+
+func snapshot(args []string, out io.Writer) error {
+	fs := flag.NewFlagSet("snapshot", flag.ContinueOnError)
+	file := fs.String("o", "", "file to write, - for stdout")
+	if _, err := parseArgs(fs, args, 0); err != nil {
+		return err
+	}
+	if *file == "" {
+		return fmt.Errorf("snapshot: -o is required\n\n%s", usage)
+	}
+
+	s, err := modle.GetDao().Snapshot()
+	if err != nil {
+		return err
+	}
+	if *file == "-" {
+		return modle.WriteSnapshot(out, s)
+	}
+	f, err := os.Create(*file)
+	if err != nil {
+		return err
+	}
+	if err = modle.WriteSnapshot(f, s); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+func diff(args []string, out io.Writer) error {
+	fs := flag.NewFlagSet("diff", flag.ContinueOnError)
+	format := fs.String("o", "table", "output format: table, json or yaml")
+	pos, err := parseArgs(fs, args, -1)
+	if err != nil {
+		return err
+	}
+	if len(pos) != 1 && len(pos) != 2 {
+		return fmt.Errorf("diff: expect 1 or 2 snapshot files, got %d\n\n%s", len(pos), usage)
+	}
+
+	old, err := modle.ReadSnapshotFile(pos[0])
+	if err != nil {
+		return err
+	}
+	var now *modle.Snapshot
+	if len(pos) == 2 {
+		now, err = modle.ReadSnapshotFile(pos[1])
+	} else {
+		now, err = modle.GetDao().Snapshot()
+	}
+	if err != nil {
+		return err
+	}
+	return write(out, *format, typedTable(views.DiffFields(), modle.Diff(old, now)))
+}
+
+// parseArgs parse flags placed before or after positional arguments, n positional arguments are required, -1 for any
+func parseArgs(fs *flag.FlagSet, args []string, n int) ([]string, error) {
+	var defaults bytes.Buffer
+	fs.SetOutput(&defaults)
+	fs.PrintDefaults()
+	fs.SetOutput(ioutil.Discard)
+
+	var pos []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, fmt.Errorf("%s: %v\n\n%s", fs.Name(), err, defaults.String())
+		}
+		if fs.NArg() == 0 {
+			break
+		}
+		pos = append(pos, fs.Arg(0))
+		args = fs.Args()[1:]
+	}
+	if n >= 0 && len(pos) != n {
+		return nil, fmt.Errorf("%s: expect %d argument, got %d\n\n%s", fs.Name(), n, len(pos), usage)
+	}
+	return pos, nil
+}
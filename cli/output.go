@@ -0,0 +1,168 @@
+/*
+ Copyright 2020  l1b0k
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/l1b0k/volans/views"
+	"gopkg.in/yaml.v2"
+)
+
+// object keep the key order of fields when marshaled
+type object []member
+
+type member struct {
+	Key   string
+	Value interface{}
+}
+
+func (o object) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, m := range o {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		k, err := json.Marshal(m.Key)
+		if err != nil {
+			return nil, err
+		}
+		v, err := json.Marshal(m.Value)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(k)
+		buf.WriteByte(':')
+		buf.Write(v)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// table is the result of a command, value is marshaled as json or yaml and rows are printed as table
+type table struct {
+	fields []views.Field
+	value  interface{}
+	rows   [][]string
+}
+
+// typedTable make a table of items, a slice of modle types such as []modle.Process, formatted by fields
+func typedTable(fields []views.Field, items interface{}) table {
+	if reflect.ValueOf(items).Len() == 0 {
+		// print [] rather than null
+		items = []interface{}{}
+	}
+	return table{fields: fields, value: items, rows: views.RowsOf(fields, items)}
+}
+
+// write print t in format
+func write(out io.Writer, format string, t table) error {
+	switch format {
+	case "table":
+		return writeTable(out, t)
+	case "json", "yaml":
+		return marshal(out, format, t.value)
+	}
+	return fmt.Errorf("unknown output format %s", format)
+}
+
+// writeSections print a namespace and all its sections in format
+func writeSections(out io.Writer, format, ns, nsType string, sections []section) error {
+	switch format {
+	case "table":
+		fmt.Fprintf(out, "NS: %s\nTYPE: %s\n", ns, nsType)
+		for _, s := range sections {
+			fmt.Fprintf(out, "\n[%s]\n", s.name)
+			t, err := s.load(ns)
+			if err != nil {
+				return fmt.Errorf("%s: %v", s.name, err)
+			}
+			if err := writeTable(out, t); err != nil {
+				return err
+			}
+		}
+		return nil
+	case "json", "yaml":
+		o := object{{Key: "ns", Value: ns}, {Key: "type", Value: nsType}}
+		for _, s := range sections {
+			t, err := s.load(ns)
+			if err != nil {
+				return fmt.Errorf("%s: %v", s.name, err)
+			}
+			o = append(o, member{Key: s.name, Value: t.value})
+		}
+		return marshal(out, format, o)
+	}
+	return fmt.Errorf("unknown output format %s", format)
+}
+
+// writeTable print t as aligned columns, hidden fields are skipped
+func writeTable(out io.Writer, t table) error {
+	w := tabwriter.NewWriter(out, 0, 8, 2, ' ', 0)
+	head := make([]string, 0, len(t.fields))
+	for _, f := range t.fields {
+		if !f.Hide {
+			head = append(head, f.Text)
+		}
+	}
+	fmt.Fprintln(w, strings.Join(head, "\t"))
+	for _, row := range t.rows {
+		cells := make([]string, 0, len(head))
+		for c, v := range row {
+			if c < len(t.fields) && !t.fields[c].Hide {
+				cells = append(cells, v)
+			}
+		}
+		fmt.Fprintln(w, strings.Join(cells, "\t"))
+	}
+	return w.Flush()
+}
+
+// marshal print v as json, or as yaml converted from json so json tags and key order are kept
+func marshal(out io.Writer, format string, v interface{}) error {
+	if format == "yaml" {
+		b, err := json.Marshal(v)
+		if err != nil {
+			return err
+		}
+		var s yaml.MapSlice
+		var l []yaml.MapSlice
+		var y interface{} = &l
+		if len(b) > 0 && b[0] == '{' {
+			y = &s
+		}
+		if err = yaml.Unmarshal(b, y); err != nil {
+			return err
+		}
+		b, err = yaml.Marshal(y)
+		if err != nil {
+			return err
+		}
+		_, err = out.Write(b)
+		return err
+	}
+	enc := json.NewEncoder(out)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
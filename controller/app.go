@@ -17,7 +17,12 @@
 package controller
 
 import (
+	"fmt"
 	"sync"
+	"time"
+
+	"github.com/l1b0k/volans/modle"
+	"github.com/l1b0k/volans/views"
 
 	"github.com/gdamore/tcell/v2"
 	"github.com/rivo/tview"
@@ -26,6 +31,28 @@ import (
 var app *App
 var once sync.Once
 
+// Config of the UI, set by Configure before GetApp
+type Config struct {
+	// ProcColumns is the columns shown in the proc pane, empty for the default ones
+	ProcColumns []string
+}
+
+var config Config
+
+// Configure set config used by GetApp, it has no effect once App created
+func Configure(c Config) error {
+	if len(c.ProcColumns) > 0 {
+		if err := views.SelectFields(views.ProcFields(), c.ProcColumns); err != nil {
+			return err
+		}
+	}
+	config = c
+	return nil
+}
+
+// sampleInterval is how often controllers showing rates take a new sample
+const sampleInterval = 2 * time.Second
+
 type App struct {
 	Tables  []Interface
 	Current int
@@ -33,19 +60,39 @@ type App struct {
 	*tview.Application
 	rootView *tview.Pages
 
-	nsController    *NSController
-	netNSController *NetNSController
-	procController  *ProcController
+	nsController   *NSController
+	procController *ProcController
+
+	// details hold a controller for each ns_type, shown in detailView
+	details    map[string]Interface
+	detailView *tview.Pages
+	detail     Interface
 
 	infoController *InfoController
+
+	// baseline is the snapshot marked by F2, F3 show what changed since then
+	baseline       *modle.Snapshot
+	diffController *DiffController
+
+	historyController *HistoryController
+
+	procInfoController *ProcInfoController
+	ifaceController    *InterfaceInfoController
+	topologyController *TopologyController
+
+	// refresh ask refreshLoop for an immediate cycle
+	refresh chan struct{}
 }
 
 // GetApp return instance
 func GetApp() *App {
 	once.Do(func() {
-		app = &App{}
+		app = &App{
+			refresh: make(chan struct{}, 1),
+		}
 		app.createViews()
 		app.setKeys()
+		go app.sample()
 	})
 	return app
 }
@@ -55,10 +102,19 @@ func (a *App) createViews() {
 	a.Application = tview.NewApplication()
 
 	a.infoController = NewInfoController()
-	a.netNSController = NewNetNSController()
 	a.procController = NewProcController()
 
+	a.details = make(map[string]Interface, len(detailControllers))
+	a.detailView = views.NewDetailView()
+	a.detailView.AddPage(emptyDetail, views.NewEmptyView("detail"), true, true)
+	for nsType, fn := range detailControllers {
+		c := fn()
+		a.details[nsType] = c
+		a.detailView.AddPage(nsType, c, true, false)
+	}
+
 	a.nsController = NewNSController()
+	a.Tables = append(a.Tables, a.nsController, a.procController)
 	a.nsController.Reload(nil)
 	a.nsController.SetSelectionChangedFunc(func(row, column int) {
 		if row <= 0 {
@@ -76,23 +132,39 @@ func (a *App) createViews() {
 			AddItem(a.nsController, 0, 1, true).
 			AddItem(tview.NewFlex().
 				SetDirection(tview.FlexRow).
-				AddItem(a.netNSController, 0, 1, false).
+				AddItem(a.detailView, 0, 1, false).
 				AddItem(a.procController, 0, 1, false),
 				0, 4, false), 0, 1, true).
 		AddItem(a.infoController, 1, 1, false)
 
+	a.diffController = NewDiffController()
+	a.historyController = NewHistoryController()
+	a.procInfoController = NewProcInfoController()
+	a.ifaceController = NewInterfaceInfoController()
+	a.topologyController = NewTopologyController()
+
 	a.rootView = tview.NewPages()
 	a.rootView.AddPage("main", layout, true, true)
+	a.rootView.AddPage(diffPage, a.diffController, true, false)
+	a.rootView.AddPage(historyPage, a.historyController, true, false)
+	a.rootView.AddPage(procInfoPage, views.NewModal(a.procInfoController), true, false)
+	a.rootView.AddPage(ifacePage, views.NewModal(a.ifaceController), true, false)
+	a.rootView.AddPage(topologyPage, a.topologyController, true, false)
 
 	a.SetRoot(a.rootView, true)
-
-	a.Tables = append(a.Tables, a.nsController, a.netNSController, a.procController)
 }
 
 func (a *App) setKeys() {
 	a.nsController.SetKeybinding(a)
-	a.netNSController.SetKeybinding(a)
+	for _, c := range a.details {
+		c.SetKeybinding(a)
+	}
 	a.procController.SetKeybinding(a)
+	a.diffController.SetKeybinding(a)
+	a.historyController.SetKeybinding(a)
+	a.procInfoController.SetKeybinding(a)
+	a.ifaceController.SetKeybinding(a)
+	a.topologyController.SetKeybinding(a)
 }
 
 func (a *App) setGlobalKeybinding(event *tcell.EventKey) {
@@ -101,8 +173,16 @@ func (a *App) setGlobalKeybinding(event *tcell.EventKey) {
 		a.Next()
 	case tcell.KeyBacktab:
 	//a.Previous()
+	case tcell.KeyF2:
+		a.MarkBaseline()
+	case tcell.KeyF3:
+		a.ShowDiff()
+	case tcell.KeyF4:
+		a.ShowHistory()
 	case tcell.KeyF5:
-
+		a.Refresh()
+	case tcell.KeyF6:
+		a.ShowTopology()
 	case tcell.KeyF12:
 		a.Application.Stop()
 	}
@@ -128,7 +208,253 @@ func (a *App) Previous() {
 	// TODO
 }
 
+// StartRefresh rescan processes and containers every interval in background.
+// With interval <= 0 rescan only happen on F5.
+func (a *App) StartRefresh(interval time.Duration) {
+	go a.refreshLoop(interval)
+}
+
+// Refresh ask for an immediate rescan, it does not block
+func (a *App) Refresh() {
+	select {
+	case a.refresh <- struct{}{}:
+	default:
+	}
+}
+
+func (a *App) refreshLoop(interval time.Duration) {
+	var tick <-chan time.Time
+	if interval > 0 {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		tick = ticker.C
+	}
+	dao := modle.GetDao()
+	dao.Record(time.Now())
+	for {
+		select {
+		case <-tick:
+		case <-a.refresh:
+		}
+		// scan out of the ui goroutine, runtime api may be slow
+		dao.Run()
+		dao.LoadProcData()
+		dao.Record(time.Now())
+		a.QueueUpdateDraw(func() {
+			// keep selection, ns table reload detail on select
+			a.nsController.Reload(nil)
+		})
+	}
+}
+
+// MarkBaseline take a snapshot in background as the baseline of ShowDiff
+func (a *App) MarkBaseline() {
+	a.infoController.SetStatus("taking baseline...")
+	go func() {
+		s, err := modle.GetDao().Snapshot()
+		a.QueueUpdateDraw(func() {
+			if err != nil {
+				a.infoController.SetStatus("[red]baseline failed: " + tview.Escape(err.Error()))
+				return
+			}
+			a.baseline = s
+			a.infoController.SetStatus("baseline " + s.Meta.Time.Format("15:04:05"))
+		})
+	}()
+}
+
+// ShowDiff take a snapshot in background, and show what changed since the baseline
+func (a *App) ShowDiff() {
+	base := a.baseline
+	if base == nil {
+		a.infoController.SetStatus("[red]press F2 to mark a baseline first")
+		return
+	}
+	a.infoController.SetStatus("comparing with baseline...")
+	go func() {
+		s, err := modle.GetDao().Snapshot()
+		a.QueueUpdateDraw(func() {
+			if err != nil {
+				a.infoController.SetStatus("[red]diff failed: " + tview.Escape(err.Error()))
+				return
+			}
+			a.infoController.SetStatus("baseline " + base.Meta.Time.Format("15:04:05"))
+			a.diffController.Reload(modle.Diff(base, s))
+			a.showOverlay(diffPage, a.diffController)
+		})
+	}()
+}
+
+// ShowHistory show how the selected namespace evolved, samples are recorded only with an on-disk database
+func (a *App) ShowHistory() {
+	if !modle.GetDao().Recording() {
+		a.infoController.SetStatus("[red]start with -db to record history")
+		return
+	}
+	row, _ := a.nsController.GetSelection()
+	if row <= 0 || row >= a.nsController.GetRowCount() {
+		return
+	}
+	a.historyController.Reload(a.nsController.GetCell(row, 0).Text)
+	a.showOverlay(historyPage, a.historyController)
+}
+
+// ShowProcInfo read the detail of pid in background, and show it in a modal
+func (a *App) ShowProcInfo(pid int) {
+	if pid < 0 {
+		return
+	}
+	go func() {
+		info, err := modle.GetDao().GetProcInfo(pid)
+		a.QueueUpdateDraw(func() {
+			if err != nil {
+				a.infoController.SetStatus(fmt.Sprintf("[red]process %d: %s", pid, tview.Escape(err.Error())))
+				return
+			}
+			a.procInfoController.Reload(info)
+			a.showOverlay(procInfoPage, a.procInfoController)
+		})
+	}()
+}
+
+// ShowInterface read what ethtool tell about the interface name in net namespace ns in background, and show it in a modal
+func (a *App) ShowInterface(ns, name string) {
+	go func() {
+		info, err := modle.GetDao().GetInterfaceInfo(ns, name)
+		a.QueueUpdateDraw(func() {
+			if err != nil {
+				a.infoController.SetStatus(fmt.Sprintf("[red]interface %s: %s", tview.Escape(name), tview.Escape(err.Error())))
+				return
+			}
+			a.ifaceController.Select(0, 0)
+			a.ifaceController.Reload(info)
+			a.ifaceController.ScrollToBeginning()
+			a.showOverlay(ifacePage, a.ifaceController)
+		})
+	}()
+}
+
+// ShowTopology collect links of all net namespaces in background, and show how they connect
+func (a *App) ShowTopology() {
+	a.infoController.SetStatus("collecting topology...")
+	go func() {
+		nodes, err := modle.GetDao().GetTopology()
+		a.QueueUpdateDraw(func() {
+			if err != nil {
+				a.infoController.SetStatus("[red]topology failed: " + tview.Escape(err.Error()))
+				return
+			}
+			a.infoController.SetStatus("")
+			a.topologyController.Reload(nodes)
+			a.showOverlay(topologyPage, a.topologyController)
+		})
+	}()
+}
+
+// showOverlay show page above the main layout and focus on c
+func (a *App) showOverlay(page string, c tview.Primitive) {
+	a.HideOverlay()
+	a.rootView.ShowPage(page)
+	a.SetFocus(c)
+}
+
+// HideOverlay close pages above the main layout and focus back on the table before them
+func (a *App) HideOverlay() {
+	a.rootView.HidePage(diffPage)
+	a.rootView.HidePage(historyPage)
+	a.rootView.HidePage(procInfoPage)
+	a.rootView.HidePage(ifacePage)
+	a.rootView.HidePage(topologyPage)
+	a.SetFocus(a.Tables[a.Current])
+}
+
+// sample let the shown detail and processes take a new sample every sampleInterval.
+// Data is collected on this goroutine, the UI goroutine only decide what to sample and show the result.
+func (a *App) sample() {
+	ticker := time.NewTicker(sampleInterval)
+	defer ticker.Stop()
+	for range ticker.C {
+		collects := make(chan []func() func(), 1)
+		a.QueueUpdate(func() {
+			var c []func() func()
+			if s, ok := a.detail.(Sampler); ok {
+				if collect := s.Sample(); collect != nil {
+					c = append(c, collect)
+				}
+			}
+			if collect := a.procController.Sample(); collect != nil {
+				c = append(c, collect)
+			}
+			if page, _ := a.rootView.GetFrontPage(); page == ifacePage {
+				if collect := a.ifaceController.Sample(); collect != nil {
+					c = append(c, collect)
+				}
+			}
+			collects <- c
+		})
+		var shows []func()
+		for _, collect := range <-collects {
+			shows = append(shows, collect())
+		}
+		a.QueueUpdateDraw(func() {
+			for _, show := range shows {
+				show()
+			}
+		})
+	}
+}
+
+// SelectNamespace select the row of namespace ns with type nsType, its detail is reloaded.
+// It return false if ns is not listed.
+func (a *App) SelectNamespace(ns, nsType string) bool {
+	for r := 1; r < a.nsController.GetRowCount(); r++ {
+		if a.nsController.GetCell(r, 0).Text == ns && a.nsController.GetCell(r, 1).Text == nsType {
+			a.nsController.Select(r, 0)
+			return true
+		}
+	}
+	return false
+}
+
 func (a *App) ReloadDetail(row, col int) {
-	a.netNSController.Reload(a.nsController.GetCell(row, 0).Text)
-	a.procController.Reload(a.nsController.GetCell(row, 0).Text)
+	ns := a.nsController.GetCell(row, 0).Text
+	a.switchDetail(a.nsController.GetCell(row, 1).Text)
+	if a.detail != nil {
+		a.detail.Reload(ns)
+	}
+	a.procController.Reload(ns)
+}
+
+// switchDetail show the detail controller registered for nsType and keep Tables in layout order
+func (a *App) switchDetail(nsType string) {
+	c, ok := a.details[nsType]
+	if !ok {
+		nsType = emptyDetail
+	}
+	a.detailView.SwitchToPage(nsType)
+	if c == a.detail {
+		return
+	}
+
+	// focus go back to ns table if it was on the detail pane being replaced
+	current := a.Tables[a.Current]
+	if current != a.nsController && current != a.procController {
+		current.UnFocus()
+		a.Current = 0
+		a.nsController.SetFocus()
+		a.SetFocus(a.nsController)
+	}
+	onProc := current == a.procController
+
+	a.detail = c
+	a.Tables = []Interface{a.nsController}
+	if g, ok := c.(Group); ok {
+		a.Tables = append(a.Tables, g.Items()...)
+	} else if c != nil {
+		a.Tables = append(a.Tables, c)
+	}
+	a.Tables = append(a.Tables, a.procController)
+	if onProc {
+		a.Current = len(a.Tables) - 1
+	}
 }
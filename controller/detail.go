@@ -0,0 +1,50 @@
+/*
+ Copyright 2020  l1b0k
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package controller
+
+import (
+	"github.com/l1b0k/volans/modle"
+	"github.com/l1b0k/volans/views"
+)
+
+// emptyDetail is the page shown when the selected namespace type has no detail controller
+const emptyDetail = ""
+
+// detailControllers map ns_type to the constructor of the controller shown in detail pane
+var detailControllers = map[string]func() Interface{
+	"cgroup": func() Interface {
+		return NewRowsController(views.NewCgroupView(), views.CgroupFields(),
+			typedLoad(func(ns string) interface{} { return modle.GetDao().GetCgroupDetail(ns) }))
+	},
+	"ipc": func() Interface {
+		return NewRowsController(views.NewIPCView(), views.IPCFields(),
+			typedLoad(func(ns string) interface{} { return modle.GetDao().GetIPCDetail(ns) }))
+	},
+	"mnt": func() Interface {
+		return NewRowsController(views.NewMntView(), views.MntFields(),
+			typedLoad(func(ns string) interface{} { return modle.GetDao().GetMountDetail(ns) }))
+	},
+	"net": func() Interface { return NewNetController() },
+	"user": func() Interface {
+		return NewRowsController(views.NewUserView(), views.UserFields(),
+			typedLoad(func(ns string) interface{} { return modle.GetDao().GetUserDetail(ns) }))
+	},
+	"uts": func() Interface {
+		return NewRowsController(views.NewUTSView(), views.UTSFields(),
+			typedLoad(func(ns string) interface{} { return modle.GetDao().GetUTSDetail(ns) }))
+	},
+}
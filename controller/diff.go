@@ -0,0 +1,85 @@
+/*
+ Copyright 2020  l1b0k
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package controller
+
+import (
+	"fmt"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/l1b0k/volans/modle"
+	"github.com/l1b0k/volans/views"
+
+	"github.com/rivo/tview"
+)
+
+// diffPage is the name of the page showing DiffController in root view
+const diffPage = "diff"
+
+// DiffController show changes between the baseline and now, above the main layout
+type DiffController struct {
+	*tview.Table
+
+	Fields []views.Field
+}
+
+func NewDiffController() *DiffController {
+	return &DiffController{
+		Table:  views.NewDiffView(),
+		Fields: views.DiffFields(),
+	}
+}
+
+func (n *DiffController) Reload(v interface{}) {
+	changes, ok := v.([]modle.Change)
+	if !ok {
+		return
+	}
+	rows := make([]interface{}, 0, len(changes))
+	for _, c := range changes {
+		rows = append(rows, c)
+	}
+	fillTable(n.Table, n.Fields, views.Rows(n.Fields, rows))
+	n.SetTitle(fmt.Sprintf("diff, %d changes (Esc to close)", len(changes)))
+	n.ScrollToBeginning()
+}
+
+func (n *DiffController) SetKeybinding(a *App) {
+	n.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		switch event.Key() {
+		case tcell.KeyEscape:
+			a.HideOverlay()
+			return nil
+		case tcell.KeyTab, tcell.KeyBacktab:
+			// tables below are not reachable while diff is shown
+			return event
+		}
+		a.setGlobalKeybinding(event)
+		return event
+	})
+}
+
+func (n *DiffController) SetFocus() {
+	n.SetSelectable(true, false)
+}
+
+func (n *DiffController) UnFocus() {
+	n.SetSelectable(false, false)
+}
+
+func (n *DiffController) Info() {
+
+}
@@ -0,0 +1,101 @@
+/*
+ Copyright 2020  l1b0k
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package controller
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/l1b0k/volans/modle"
+	"github.com/l1b0k/volans/views"
+
+	"github.com/rivo/tview"
+)
+
+// historyPage is the name of the page showing HistoryController in root view
+const historyPage = "history"
+
+// historyWindow is how far back history is shown, split into historyBuckets
+const (
+	historyWindow  = 6 * time.Hour
+	historyBuckets = 72
+)
+
+// HistoryController show how process count and interface drops of a namespace evolved
+type HistoryController struct {
+	*tview.Table
+
+	Dao    *modle.Dao
+	Fields []views.Field
+}
+
+func NewHistoryController() *HistoryController {
+	return &HistoryController{
+		Table:  views.NewHistoryView(),
+		Dao:    modle.GetDao(),
+		Fields: views.HistoryFields(),
+	}
+}
+
+func (n *HistoryController) Reload(v interface{}) {
+	ns, ok := v.(string)
+	if !ok {
+		return
+	}
+	now := time.Now()
+	series, err := n.Dao.GetHistory(ns, now.Add(-historyWindow), now, historyBuckets)
+	if err != nil {
+		n.Clear()
+		n.SetTitle("history [red]" + tview.Escape(err.Error()))
+		return
+	}
+	rows := make([]interface{}, 0, len(series))
+	for _, s := range series {
+		rows = append(rows, s)
+	}
+	fillTable(n.Table, n.Fields, views.Rows(n.Fields, rows))
+	n.SetTitle(fmt.Sprintf("history of %s, last %s (Esc to close)", ns, historyWindow))
+	n.ScrollToBeginning()
+}
+
+func (n *HistoryController) SetKeybinding(a *App) {
+	n.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		switch event.Key() {
+		case tcell.KeyEscape:
+			a.HideOverlay()
+			return nil
+		case tcell.KeyTab, tcell.KeyBacktab:
+			// tables below are not reachable while history is shown
+			return event
+		}
+		a.setGlobalKeybinding(event)
+		return event
+	})
+}
+
+func (n *HistoryController) SetFocus() {
+	n.SetSelectable(true, false)
+}
+
+func (n *HistoryController) UnFocus() {
+	n.SetSelectable(false, false)
+}
+
+func (n *HistoryController) Info() {
+
+}
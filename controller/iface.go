@@ -0,0 +1,113 @@
+/*
+ Copyright 2020  l1b0k
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package controller
+
+import (
+	"fmt"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/l1b0k/volans/modle"
+	"github.com/l1b0k/volans/views"
+
+	"github.com/rivo/tview"
+)
+
+// ifacePage is the name of the page showing InterfaceInfoController in root view
+const ifacePage = "iface"
+
+// InterfaceInfoController show driver, link, ring, coalesce, features and counters of an interface
+type InterfaceInfoController struct {
+	*tview.Table
+
+	Dao    *modle.Dao
+	Fields []views.Field
+
+	info *modle.InterfaceInfo
+}
+
+func NewInterfaceInfoController() *InterfaceInfoController {
+	return &InterfaceInfoController{
+		Table:  views.NewInterfaceInfoView(),
+		Dao:    modle.GetDao(),
+		Fields: views.InterfaceInfoFields(),
+	}
+}
+
+// Reload show a *modle.InterfaceInfo
+func (n *InterfaceInfoController) Reload(v interface{}) {
+	info, ok := v.(*modle.InterfaceInfo)
+	if !ok {
+		return
+	}
+	n.info = info
+	lines := views.InterfaceInfoRows(info)
+	rows := make([]interface{}, 0, len(lines))
+	for _, r := range lines {
+		rows = append(rows, r)
+	}
+	row, _ := n.GetSelection()
+	fillTable(n.Table, n.Fields, views.Rows(n.Fields, rows))
+	if row > 0 && row < n.GetRowCount() {
+		n.Select(row, 0)
+	}
+	n.SetTitle(fmt.Sprintf("%s in %s (Esc to close)", info.Name, info.NS))
+}
+
+// Sample read the interface again, so counter rates are refreshed
+func (n *InterfaceInfoController) Sample() func() func() {
+	if n.info == nil {
+		return nil
+	}
+	shown := n.info
+	return func() func() {
+		info, err := n.Dao.GetInterfaceInfo(shown.NS, shown.Name)
+		return func() {
+			// another interface may be shown meanwhile
+			if err != nil || n.info != shown {
+				return
+			}
+			n.Reload(info)
+		}
+	}
+}
+
+func (n *InterfaceInfoController) SetKeybinding(a *App) {
+	n.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		switch event.Key() {
+		case tcell.KeyEscape:
+			a.HideOverlay()
+			return nil
+		case tcell.KeyTab, tcell.KeyBacktab:
+			// tables below are not reachable while the interface is shown
+			return event
+		}
+		a.setGlobalKeybinding(event)
+		return event
+	})
+}
+
+func (n *InterfaceInfoController) SetFocus() {
+	n.SetSelectable(true, false)
+}
+
+func (n *InterfaceInfoController) UnFocus() {
+	n.SetSelectable(false, false)
+}
+
+func (n *InterfaceInfoController) Info() {
+
+}
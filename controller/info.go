@@ -18,12 +18,18 @@ package controller
 
 import (
 	"fmt"
+	"time"
+
+	"github.com/l1b0k/volans/modle"
 
 	"github.com/rivo/tview"
 )
 
 type InfoController struct {
 	*tview.TextView
+
+	// status is a message shown after hints, such as the time of baseline
+	status string
 }
 
 func NewInfoController() *InfoController {
@@ -33,11 +39,26 @@ func NewInfoController() *InfoController {
 			SetRegions(true).
 			SetWrap(false),
 	}
-	hints := [][]string{{"Tab", "toggle"}, {"F5", "refresh"}, {"F12", "quit"}}
+	infoView.render()
+	return infoView
+}
+
+func (n *InfoController) render() {
+	n.Clear()
+	hints := [][]string{{"Tab", "toggle"}, {"Enter", "detail"}, {"p", "peer"}, {"Space", "fold"}, {"w", "cmdline"}, {"F2", "baseline"}, {"F3", "diff"}, {"F4", "history"}, {"F5", "refresh"}, {"F6", "topology"}, {"F12", "quit"}}
 	for i := 0; i < len(hints); i++ {
-		fmt.Fprintf(infoView, `%s ["%d"][darkcyan]%s[white][""]  `, hints[i][0], i, hints[i][1])
+		fmt.Fprintf(n, `%s ["%d"][darkcyan]%s[white][""]  `, hints[i][0], i, hints[i][1])
 	}
-	return infoView
+	if meta := modle.GetDao().Replay(); meta != nil {
+		fmt.Fprintf(n, "[red]replay[white] %s %s  ", meta.Hostname, meta.Time.Format(time.RFC3339))
+	}
+	fmt.Fprintf(n, "%s[white]", n.status)
+}
+
+// SetStatus replace the message after hints, it may contain color tags
+func (n *InfoController) SetStatus(status string) {
+	n.status = status
+	n.render()
 }
 
 func (n *InfoController) Reload(v interface{}) *InfoController {
@@ -29,3 +29,23 @@ type Interface interface {
 	UnFocus()
 	Info()
 }
+
+// Group is a controller made of several tables, each of them take part in Tab order
+type Group interface {
+	Interface
+
+	Items() []Interface
+}
+
+// Sampler is a controller showing rates, Sample is called periodically on the UI goroutine to take a new sample.
+// It return a function collecting the data, run on the sampling goroutine so a slow namespace does not freeze the UI,
+// which in turn return a function showing the data, run on the UI goroutine. Sample return nil if nothing is shown.
+type Sampler interface {
+	Sample() func() func()
+}
+
+// Loader is a controller reading its data off the UI goroutine. Load is called on a background goroutine and must not
+// touch the view, it return a function showing the data, run on the UI goroutine.
+type Loader interface {
+	Load(ns string) func()
+}
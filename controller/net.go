@@ -0,0 +1,107 @@
+/*
+ Copyright 2020  l1b0k
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package controller
+
+import (
+	"github.com/l1b0k/volans/modle"
+	"github.com/l1b0k/volans/views"
+
+	"github.com/rivo/tview"
+)
+
+// NetController is the detail of a net namespace, links on top and tabs of sockets, routes, rules and neighbors below
+type NetController struct {
+	*tview.Flex
+
+	links *NetNSController
+	tabs  *TabController
+}
+
+func NewNetController() *NetController {
+	dao := modle.GetDao()
+	routing := &routingLoader{
+		Dao: dao,
+		routes: NewRowsController(views.NewRouteView(), views.RouteFields(),
+			typedLoad(func(ns string) interface{} { return dao.GetRouteDetail(ns) })),
+		rules: NewRowsController(views.NewRuleView(), views.RuleFields(),
+			typedLoad(func(ns string) interface{} { return dao.GetRuleDetail(ns) })),
+		neighs: NewRowsController(views.NewNeighView(), views.NeighFields(),
+			typedLoad(func(ns string) interface{} { return dao.GetNeighDetail(ns) })),
+	}
+	sockets := NewRowsController(views.NewSocketView(), views.SocketFields(),
+		typedLoad(func(ns string) interface{} { return dao.GetSocketDetail(ns) }))
+	n := &NetController{
+		Flex:  tview.NewFlex().SetDirection(tview.FlexRow),
+		links: NewNetNSController(),
+		tabs: NewTabController(
+			[]string{"sockets", "routes", "rules", "neigh"},
+			[]Interface{sockets, routing.routes, routing.rules, routing.neighs},
+		).SetLoader(1, routing).SetLoader(2, routing).SetLoader(3, routing),
+	}
+	n.AddItem(n.links, 0, 1, true).
+		AddItem(n.tabs, 0, 1, false)
+	return n
+}
+
+func (n *NetController) Reload(v interface{}) {
+	n.links.Reload(v)
+	n.tabs.Reload(v)
+}
+
+func (n *NetController) SetKeybinding(a *App) {
+	n.links.SetKeybinding(a)
+	n.tabs.SetKeybinding(a)
+}
+
+func (n *NetController) SetFocus() {
+	n.links.SetFocus()
+}
+
+func (n *NetController) UnFocus() {
+	n.links.UnFocus()
+	n.tabs.UnFocus()
+}
+
+func (n *NetController) Info() {
+
+}
+
+func (n *NetController) Sample() func() func() {
+	return n.links.Sample()
+}
+
+// Items return tables take part in Tab order
+func (n *NetController) Items() []Interface {
+	return []Interface{n.links, n.tabs}
+}
+
+// routingLoader fill the routes, rules and neigh tabs from a single visit of the namespace
+type routingLoader struct {
+	Dao    *modle.Dao
+	routes *RowsController
+	rules  *RowsController
+	neighs *RowsController
+}
+
+func (r *routingLoader) Load(ns string) func() {
+	data := r.Dao.GetRoutingDetail(ns)
+	return func() {
+		r.routes.show(views.Items(data.Routes), nil)
+		r.rules.show(views.Items(data.Rules), nil)
+		r.neighs.show(views.Items(data.Neighs), nil)
+	}
+}
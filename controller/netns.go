@@ -29,31 +29,17 @@ type NetNSController struct {
 
 	Dao    *modle.Dao
 	Fields []views.Field
+
+	// ns is the namespace shown, kept for Sample, ifaces is the rows shown, in order
+	ns     string
+	ifaces []modle.Interface
 }
 
 func NewNetNSController() *NetNSController {
 	return &NetNSController{
-		Table: views.NewNetNSView(),
-		Dao:   modle.GetDao(),
-		Fields: []views.Field{
-			{Text: "IF", Cell: views.CellAlignLeft},
-			{Text: "Type", Cell: views.CellAlignRight},
-			{Text: "MAC", Cell: views.CellAlignRight},
-			{Text: "CH", Cell: views.CellAlignRight},
-			{Text: "IP", Cell: views.CellAlignRight},
-			{Text: "rxErr", Cell: views.CellAlignRight},
-			{Text: "rxDrop", Cell: views.CellAlignRight},
-			{Text: "txErr", Cell: views.CellAlignRight},
-			{Text: "txDrop", Cell: views.CellAlignRight},
-			{Text: "MTU", Cell: views.CellAlignRight},
-			{Text: "Flag", Cell: views.CellAlignRight},
-			{Text: "GSO", Cell: views.CellAlignRight},
-			{Text: "TSO", Cell: views.CellAlignRight},
-			{Text: "LRO", Cell: views.CellAlignRight},
-			{Text: "GRO", Cell: views.CellAlignRight},
-			{Text: "SG", Cell: views.CellAlignRight},
-			{Text: "CSUM[rx/tx]", Cell: views.CellAlignRight},
-		},
+		Table:  views.NewNetNSView(),
+		Dao:    modle.GetDao(),
+		Fields: views.NetNSFields(),
 	}
 }
 
@@ -62,34 +48,90 @@ func (n *NetNSController) Reload(v interface{}) {
 	if !ok {
 		return
 	}
-	// clear table
-	n.Clear()
-	// fill head
-	skipped := 0
-	for c, f := range n.Fields {
-		if f.Hide {
-			skipped++
-			continue
+	n.ns = ns
+	ifaces, err := n.Dao.GetNetNSDetail(ns)
+	n.show(ifaces, err)
+}
+
+// show fill the table with interfaces of the shown namespace, or the error reading them
+func (n *NetNSController) show(ifaces []modle.Interface, err error) {
+	if err != nil {
+		// keep the UI running, the namespace may be gone or not readable
+		n.ifaces = nil
+		fillTable(n.Table, n.Fields, nil)
+		n.SetTitle("net [red]" + tview.Escape(err.Error()))
+		return
+	}
+	n.SetTitle("net")
+	n.ifaces = ifaces
+	rows := make([]interface{}, 0, len(n.ifaces))
+	for _, r := range n.ifaces {
+		rows = append(rows, r)
+	}
+	fillTable(n.Table, n.Fields, views.Rows(n.Fields, rows))
+	// highlight interface name when any of its rates alert
+	for r := 1; r < n.GetRowCount(); r++ {
+		for c := 1; c < n.GetColumnCount(); c++ {
+			if alert, ok := n.GetCell(r, c).GetReference().(views.Alert); ok && bool(alert) {
+				n.GetCell(r, 0).SetTextColor(tcell.ColorRed)
+				break
+			}
 		}
-		n.SetCell(0, c-skipped, views.CellTitle(f.Text))
 	}
-	// fill data
-	data := n.Dao.GetNetNSDetail(ns)
-	skipped = 0
-	for r := 0; r < len(data); r++ {
-		for c := 0; c < len(data[0]); c++ {
-			f := n.Fields[c]
-			if f.Hide {
-				skipped++
-				continue
+}
+
+// Sample read the shown namespace again, so rates are refreshed
+func (n *NetNSController) Sample() func() func() {
+	ns := n.ns
+	if ns == "" {
+		return nil
+	}
+	return func() func() {
+		ifaces, err := n.Dao.GetNetNSDetail(ns)
+		return func() {
+			// another namespace may be selected meanwhile
+			if n.ns == ns {
+				n.show(ifaces, err)
 			}
-			n.SetCell(r+1, c-skipped, f.Cell(data[r][c], data[r][c]))
+		}
+	}
+}
+
+// JumpToPeer select the namespace of the veth peer of the selected interface, and the peer in it
+func (n *NetNSController) JumpToPeer(a *App) {
+	row, _ := n.GetSelection()
+	if row <= 0 || row > len(n.ifaces) {
+		return
+	}
+	peer := n.ifaces[row-1].Peer
+	if peer == nil {
+		a.infoController.SetStatus("[red]no veth peer for " + tview.Escape(n.ifaces[row-1].Name))
+		return
+	}
+	if peer.NS != n.ns && !a.SelectNamespace(peer.NS, "net") {
+		a.infoController.SetStatus("[red]namespace " + tview.Escape(peer.NS) + " is not listed")
+		return
+	}
+	for i, iface := range n.ifaces {
+		if iface.Name == peer.Name {
+			n.Select(i+1, 0)
+			return
 		}
 	}
 }
 
 func (n *NetNSController) SetKeybinding(a *App) {
 	n.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		if event.Key() == tcell.KeyEnter {
+			if row, _ := n.GetSelection(); row > 0 && row <= len(n.ifaces) {
+				a.ShowInterface(n.ns, n.ifaces[row-1].Name)
+			}
+			return nil
+		}
+		if event.Key() == tcell.KeyRune && event.Rune() == 'p' {
+			n.JumpToPeer(a)
+			return nil
+		}
 		a.setGlobalKeybinding(event)
 		return event
 	})
@@ -33,45 +33,36 @@ type NSController struct {
 
 func NewNSController() *NSController {
 	return &NSController{
-		Table: views.NewNSView(),
-		Dao:   modle.GetDao(),
-		Fields: []views.Field{
-			{Text: "NS", Cell: views.CellAlignLeft},
-			{Text: "TYPE", Cell: views.CellAlignRight},
-			{Text: "NPROCS", Cell: views.CellAlignRight},
-			{Text: "POD", Cell: views.CellAlignRight},
-		},
+		Table:  views.NewNSView(),
+		Dao:    modle.GetDao(),
+		Fields: views.NSFields(),
 	}
 }
 
+// Reload refill the table, selection is kept on the same namespace if it still exist
 func (n *NSController) Reload(v interface{}) {
-	// clear table
-	n.Clear()
-	// fill head
-	skipped := 0
-	for c, f := range n.Fields {
-		if f.Hide {
-			skipped++
-			continue
-		}
-		n.SetCell(0, c-skipped, views.CellTitle(f.Text))
+	var ns, nsType string
+	row, _ := n.GetSelection()
+	if row > 0 && row < n.GetRowCount() {
+		ns, nsType = n.GetCell(row, 0).Text, n.GetCell(row, 1).Text
+	}
+
+	nss := n.Dao.GetNSWithPidCount()
+	rows := make([]interface{}, 0, len(nss))
+	for _, r := range nss {
+		rows = append(rows, r)
 	}
+	fillTable(n.Table, n.Fields, views.Rows(n.Fields, rows))
 
-	// fill data
-	data := n.Dao.GetNSWithPidCount()
-	skipped = 0
-	for r := 0; r < len(data); r++ {
-		for c := 0; c < len(data[0]); c++ {
-			f := n.Fields[c]
-			if f.Hide {
-				skipped++
-				continue
-			}
-			n.SetCell(r+1, c-skipped, f.Cell(data[r][c], data[r][c]))
+	selected := 1
+	for r := 1; r < n.GetRowCount(); r++ {
+		if n.GetCell(r, 0).Text == ns && n.GetCell(r, 1).Text == nsType {
+			selected = r
+			break
 		}
 	}
 	if n.GetRowCount() > 1 {
-		n.Select(1, 0)
+		n.Select(selected, 0)
 	}
 }
 
@@ -29,55 +29,129 @@ type ProcController struct {
 
 	Dao    *modle.Dao
 	Fields []views.Field
+
+	// ns is the namespace shown, collapsed hold pids whose children are hidden
+	ns        string
+	collapsed map[int]bool
+	// nodes is the rows shown, in order
+	nodes []views.ProcNode
 }
 
 func NewProcController() *ProcController {
+	fields := views.ProcFields()
+	if len(config.ProcColumns) > 0 {
+		// checked by Configure
+		_ = views.SelectFields(fields, config.ProcColumns)
+	}
 	return &ProcController{
-		Table: views.NewProcView(),
-		Dao:   modle.GetDao(),
-		Fields: []views.Field{
-			{Text: "PID", Cell: views.CellAlignLeft},
-			{Text: "Name", Cell: views.CellAlignRight},
-			{Text: "S", Cell: views.CellAlignRight},
-			{Text: "CPU", Cell: views.CellAlignRight},
-			{Text: "CMD", Cell: views.CellAlignLeft},
-		},
+		Table:     views.NewProcView(),
+		Dao:       modle.GetDao(),
+		Fields:    fields,
+		collapsed: map[int]bool{},
 	}
 }
 
+// Reload show processes of ns, the selected process is kept if it is still shown
 func (n *ProcController) Reload(v interface{}) {
 	ns, ok := v.(string)
 	if !ok {
 		return
 	}
-	// clear table
-	n.Clear()
-	// fill head
-	skipped := 0
-	for c, f := range n.Fields {
-		if f.Hide {
-			skipped++
-			continue
+	selected := n.selected()
+	if ns != n.ns {
+		n.ns = ns
+		n.collapsed = map[int]bool{}
+		selected = -1
+	}
+	n.show(n.Dao.GetProcDetail(ns), selected)
+}
+
+// show fill the table with processes of the shown namespace, selecting pid selected if shown
+func (n *ProcController) show(procs []modle.Process, selected int) {
+	n.nodes = views.ProcTree(procs, n.collapsed)
+	rows := make([]interface{}, 0, len(n.nodes))
+	for _, r := range n.nodes {
+		rows = append(rows, r)
+	}
+	fillTable(n.Table, n.Fields, views.Rows(n.Fields, rows))
+	for i, node := range n.nodes {
+		if node.Pid == selected {
+			n.Select(i+1, 0)
+			return
 		}
-		n.SetCell(0, c-skipped, views.CellTitle(f.Text))
 	}
-	// fill data
-	data := n.Dao.GetProcDetail(ns)
-	skipped = 0
-	for r := 0; r < len(data); r++ {
-		for c := 0; c < len(data[0]); c++ {
-			f := n.Fields[c]
-			if f.Hide {
-				skipped++
-				continue
+}
+
+// Sample read processes of the shown namespace again, so CPU% is refreshed
+func (n *ProcController) Sample() func() func() {
+	ns := n.ns
+	if ns == "" {
+		return nil
+	}
+	return func() func() {
+		procs := n.Dao.GetProcDetail(ns)
+		return func() {
+			if n.ns == ns {
+				n.show(procs, n.selected())
 			}
-			n.SetCell(r+1, c-skipped, f.Cell(data[r][c], data[r][c]))
 		}
 	}
 }
 
+// selected return pid of the selected row, -1 if none
+func (n *ProcController) selected() int {
+	row, _ := n.GetSelection()
+	if row <= 0 || row > len(n.nodes) {
+		return -1
+	}
+	return n.nodes[row-1].Pid
+}
+
+// Toggle collapse or expand children of the selected process
+func (n *ProcController) Toggle() {
+	pid := n.selected()
+	if pid < 0 {
+		return
+	}
+	n.collapsed[pid] = !n.collapsed[pid]
+	n.Reload(n.ns)
+}
+
+// ToggleCmdline switch between the truncated CMD and the full CMDLINE column, exactly one of them is shown after
+func (n *ProcController) ToggleCmdline() {
+	cmd, cmdline := -1, -1
+	for i := range n.Fields {
+		switch n.Fields[i].Text {
+		case "CMD":
+			cmd = i
+		case "CMDLINE":
+			cmdline = i
+		}
+	}
+	if cmd < 0 || cmdline < 0 {
+		return
+	}
+	n.Fields[cmd].Hide = !n.Fields[cmd].Hide
+	n.Fields[cmdline].Hide = !n.Fields[cmd].Hide
+	n.Reload(n.ns)
+}
+
 func (n *ProcController) SetKeybinding(a *App) {
 	n.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		if event.Key() == tcell.KeyEnter {
+			a.ShowProcInfo(n.selected())
+			return nil
+		}
+		if event.Key() == tcell.KeyRune {
+			switch event.Rune() {
+			case ' ':
+				n.Toggle()
+				return nil
+			case 'w':
+				n.ToggleCmdline()
+				return nil
+			}
+		}
 		a.setGlobalKeybinding(event)
 		return event
 	})
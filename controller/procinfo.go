@@ -0,0 +1,115 @@
+/*
+ Copyright 2020  l1b0k
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package controller
+
+import (
+	"fmt"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/l1b0k/volans/modle"
+	"github.com/l1b0k/volans/views"
+
+	"github.com/rivo/tview"
+)
+
+// procInfoPage is the name of the page showing ProcInfoController in root view
+const procInfoPage = "proc"
+
+// ProcInfoController show status, limits, environ, links, fds and capabilities of a process
+type ProcInfoController struct {
+	*tview.Table
+
+	Fields []views.Field
+
+	info *modle.ProcInfo
+	// reveal show values of environ, they are redacted by default as they often hold secrets
+	reveal bool
+}
+
+func NewProcInfoController() *ProcInfoController {
+	return &ProcInfoController{
+		Table:  views.NewProcInfoView(),
+		Fields: views.ProcInfoFields(),
+	}
+}
+
+// Reload show a *modle.ProcInfo, environ is redacted again
+func (n *ProcInfoController) Reload(v interface{}) {
+	info, ok := v.(*modle.ProcInfo)
+	if !ok {
+		return
+	}
+	n.info = info
+	n.reveal = false
+	n.render()
+	n.ScrollToBeginning()
+}
+
+func (n *ProcInfoController) render() {
+	if n.info == nil {
+		return
+	}
+	lines := views.ProcInfoRows(n.info, !n.reveal)
+	rows := make([]interface{}, 0, len(lines))
+	for _, r := range lines {
+		rows = append(rows, r)
+	}
+	fillTable(n.Table, n.Fields, views.Rows(n.Fields, rows))
+	environ := "r to reveal environ"
+	if n.reveal {
+		environ = "r to redact environ"
+	}
+	n.SetTitle(fmt.Sprintf("process %d, %s (Esc to close)", n.info.Pid, environ))
+}
+
+// ToggleRedact reveal or redact values of environ
+func (n *ProcInfoController) ToggleRedact() {
+	n.reveal = !n.reveal
+	n.render()
+}
+
+func (n *ProcInfoController) SetKeybinding(a *App) {
+	n.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		switch event.Key() {
+		case tcell.KeyEscape:
+			a.HideOverlay()
+			return nil
+		case tcell.KeyTab, tcell.KeyBacktab:
+			// tables below are not reachable while the process is shown
+			return event
+		case tcell.KeyRune:
+			if event.Rune() == 'r' {
+				n.ToggleRedact()
+				return nil
+			}
+		}
+		a.setGlobalKeybinding(event)
+		return event
+	})
+}
+
+func (n *ProcInfoController) SetFocus() {
+	n.SetSelectable(true, false)
+}
+
+func (n *ProcInfoController) UnFocus() {
+	n.SetSelectable(false, false)
+}
+
+func (n *ProcInfoController) Info() {
+
+}
@@ -0,0 +1,95 @@
+/*
+ Copyright 2020  l1b0k
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package controller
+
+import (
+	"github.com/gdamore/tcell/v2"
+	"github.com/l1b0k/volans/views"
+
+	"github.com/rivo/tview"
+)
+
+// RowsController show rows of a namespace read by load, one per line in columns of Fields
+type RowsController struct {
+	*tview.Table
+
+	Fields []views.Field
+
+	title string
+	load  func(ns string) ([]interface{}, error)
+}
+
+func NewRowsController(view *tview.Table, fields []views.Field, load func(ns string) ([]interface{}, error)) *RowsController {
+	return &RowsController{
+		Table:  view,
+		Fields: fields,
+		title:  view.GetTitle(),
+		load:   load,
+	}
+}
+
+// typedLoad adapt a Dao getter returning a slice of modle types to the load of a RowsController
+func typedLoad(get func(ns string) interface{}) func(ns string) ([]interface{}, error) {
+	return func(ns string) ([]interface{}, error) {
+		return views.Items(get(ns)), nil
+	}
+}
+
+func (n *RowsController) Reload(v interface{}) {
+	ns, ok := v.(string)
+	if !ok {
+		return
+	}
+	n.Load(ns)()
+}
+
+func (n *RowsController) Load(ns string) func() {
+	rows, err := n.load(ns)
+	return func() {
+		n.show(rows, err)
+	}
+}
+
+// show fill the table with rows, or the error reading them
+func (n *RowsController) show(rows []interface{}, err error) {
+	if err != nil {
+		fillTable(n.Table, n.Fields, nil)
+		n.SetTitle(n.title + " [red]" + tview.Escape(err.Error()))
+		return
+	}
+	n.SetTitle(n.title)
+	fillTable(n.Table, n.Fields, views.Rows(n.Fields, rows))
+}
+
+func (n *RowsController) SetKeybinding(a *App) {
+	n.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		a.setGlobalKeybinding(event)
+		return event
+	})
+}
+
+func (n *RowsController) SetFocus() {
+	n.SetSelectable(true, false)
+}
+
+func (n *RowsController) UnFocus() {
+	n.SetSelectable(false, false)
+}
+
+func (n *RowsController) Info() {
+
+}
@@ -0,0 +1,140 @@
+/*
+ Copyright 2020  l1b0k
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package controller
+
+import (
+	"strconv"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/l1b0k/volans/views"
+
+	"github.com/rivo/tview"
+)
+
+// TabController show one of several controllers at a time, number keys switch between them.
+// Only the shown tab is reloaded, in background when it is a Loader.
+type TabController struct {
+	*tview.Flex
+
+	bar     *tview.TextView
+	pages   *tview.Pages
+	tabs    []Interface
+	loaders []Loader
+	current int
+
+	// app queue the result of loaders, ns is the namespace shown
+	app *App
+	ns  string
+}
+
+func NewTabController(names []string, tabs []Interface) *TabController {
+	t := &TabController{
+		Flex:    tview.NewFlex().SetDirection(tview.FlexRow),
+		bar:     views.NewTabBar(names),
+		pages:   tview.NewPages(),
+		tabs:    tabs,
+		loaders: make([]Loader, len(tabs)),
+	}
+	for i, tab := range tabs {
+		t.pages.AddPage(strconv.Itoa(i), tab, true, i == 0)
+		t.loaders[i], _ = tab.(Loader)
+	}
+	t.bar.Highlight("0")
+	t.AddItem(t.bar, 1, 0, false).
+		AddItem(t.pages, 0, 1, true)
+	return t
+}
+
+// Switch show the i-th tab
+func (t *TabController) Switch(i int) {
+	if i < 0 || i >= len(t.tabs) || i == t.current {
+		return
+	}
+	focused := t.tabs[t.current].HasFocus()
+	t.tabs[t.current].UnFocus()
+	t.current = i
+	t.pages.SwitchToPage(strconv.Itoa(i))
+	t.bar.Highlight(strconv.Itoa(i))
+	if focused {
+		t.tabs[i].SetFocus()
+	}
+	t.load()
+}
+
+// SetLoader read the i-th tab with l instead of the tab itself, so tabs can share a single read of the namespace
+func (t *TabController) SetLoader(i int, l Loader) *TabController {
+	t.loaders[i] = l
+	return t
+}
+
+func (t *TabController) Reload(v interface{}) {
+	ns, ok := v.(string)
+	if !ok {
+		return
+	}
+	t.ns = ns
+	t.load()
+}
+
+// load read the shown tab again
+func (t *TabController) load() {
+	if t.ns == "" {
+		return
+	}
+	l := t.loaders[t.current]
+	if l == nil || t.app == nil {
+		t.tabs[t.current].Reload(t.ns)
+		return
+	}
+	ns := t.ns
+	go func() {
+		show := l.Load(ns)
+		t.app.QueueUpdateDraw(func() {
+			// another namespace may be selected meanwhile
+			if t.ns == ns {
+				show()
+			}
+		})
+	}()
+}
+
+func (t *TabController) SetKeybinding(a *App) {
+	t.app = a
+	for _, tab := range t.tabs {
+		tab.SetKeybinding(a)
+	}
+	t.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		if event.Key() == tcell.KeyRune && event.Rune() >= '1' && event.Rune() <= '9' {
+			t.Switch(int(event.Rune() - '1'))
+			a.SetFocus(t.tabs[t.current])
+			return nil
+		}
+		return event
+	})
+}
+
+func (t *TabController) SetFocus() {
+	t.tabs[t.current].SetFocus()
+}
+
+func (t *TabController) UnFocus() {
+	t.tabs[t.current].UnFocus()
+}
+
+func (t *TabController) Info() {
+
+}
@@ -0,0 +1,51 @@
+/*
+ Copyright 2020  l1b0k
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package controller
+
+import (
+	"github.com/l1b0k/volans/views"
+
+	"github.com/rivo/tview"
+)
+
+// fillTable clear the table, then render fields as head and data as rows. Hidden fields are skipped.
+// Data is escaped, so values like "[fixed]" or "socket:[1234]" are not taken as color tags.
+func fillTable(t *tview.Table, fields []views.Field, data [][]string) {
+	// clear table
+	t.Clear()
+	// fill head
+	skipped := 0
+	for c, f := range fields {
+		if f.Hide {
+			skipped++
+			continue
+		}
+		t.SetCell(0, c-skipped, views.CellTitle(f.Text))
+	}
+	// fill data
+	for r := 0; r < len(data); r++ {
+		skipped = 0
+		for c := 0; c < len(data[r]) && c < len(fields); c++ {
+			f := fields[c]
+			if f.Hide {
+				skipped++
+				continue
+			}
+			t.SetCell(r+1, c-skipped, f.Cell(tview.Escape(data[r][c]), data[r][c]))
+		}
+	}
+}
@@ -0,0 +1,79 @@
+/*
+ Copyright 2020  l1b0k
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package controller
+
+import (
+	"fmt"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/l1b0k/volans/modle"
+	"github.com/l1b0k/volans/views"
+
+	"github.com/rivo/tview"
+)
+
+// topologyPage is the name of the page showing TopologyController in root view
+const topologyPage = "topology"
+
+// TopologyController show how net namespaces connect to each other and to the NICs of the node
+type TopologyController struct {
+	*tview.TextView
+}
+
+func NewTopologyController() *TopologyController {
+	return &TopologyController{
+		TextView: views.NewTopologyView(),
+	}
+}
+
+// Reload show a []modle.TopologyNode
+func (n *TopologyController) Reload(v interface{}) {
+	nodes, ok := v.([]modle.TopologyNode)
+	if !ok {
+		return
+	}
+	n.SetText(views.Topology(nodes))
+	n.SetTitle(fmt.Sprintf("topology, %d net namespaces (Esc to close)", len(nodes)))
+	n.ScrollToBeginning()
+}
+
+func (n *TopologyController) SetKeybinding(a *App) {
+	n.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		switch event.Key() {
+		case tcell.KeyEscape:
+			a.HideOverlay()
+			return nil
+		case tcell.KeyTab, tcell.KeyBacktab:
+			// tables below are not reachable while topology is shown
+			return event
+		}
+		a.setGlobalKeybinding(event)
+		return event
+	})
+}
+
+func (n *TopologyController) SetFocus() {
+
+}
+
+func (n *TopologyController) UnFocus() {
+
+}
+
+func (n *TopologyController) Info() {
+
+}
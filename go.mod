@@ -3,18 +3,17 @@ module github.com/l1b0k/volans
 go 1.15
 
 require (
-	github.com/StackExchange/wmi v0.0.0-20190523213315-cbe66965904d // indirect
 	github.com/c9s/goprocinfo v0.0.0-20200311234719-5750cbd54a3b
-	github.com/containerd/containerd v1.4.3 // indirect
-	github.com/containernetworking/plugins v0.9.0
+	github.com/containerd/containerd v1.4.3
+	github.com/containernetworking/plugins v0.9.0 // indirect
 	github.com/docker/distribution v2.7.1+incompatible // indirect
 	github.com/docker/docker v20.10.1+incompatible
 	github.com/docker/go-connections v0.4.0 // indirect
 	github.com/docker/go-units v0.4.0 // indirect
 	github.com/gdamore/tcell/v2 v2.0.1-0.20201017141208-acf90d56d591
-	github.com/go-ole/go-ole v1.2.4 // indirect
 	github.com/gogo/protobuf v1.3.1 // indirect
 	github.com/gorilla/mux v1.8.0 // indirect
+	github.com/mattn/go-runewidth v0.0.9
 	github.com/mattn/go-sqlite3 v1.14.5
 	github.com/moby/term v0.0.0-20201216013528-df9cb8a40635 // indirect
 	github.com/morikuni/aec v1.0.0 // indirect
@@ -22,12 +21,13 @@ require (
 	github.com/opencontainers/image-spec v1.0.1 // indirect
 	github.com/rivo/tview v0.0.0-20201204190810-5406288b8e4e
 	github.com/safchain/ethtool v0.0.0-20201023143004-874930cb3ce0
-	github.com/shirou/gopsutil v3.20.11+incompatible
 	github.com/vishvananda/netlink v1.1.1-0.20201029203352-d40f9887b852
-	golang.org/x/sys v0.0.0-20201201145000-ef89a241ccb3 // indirect
+	golang.org/x/sys v0.0.0-20201201145000-ef89a241ccb3
 	golang.org/x/time v0.0.0-20201208040808-7e3f01d25324 // indirect
-	google.golang.org/grpc v1.34.0 // indirect
+	google.golang.org/grpc v1.34.0
+	gopkg.in/yaml.v2 v2.3.0
 	gorm.io/driver/sqlite v1.1.4
 	gorm.io/gorm v1.20.8
 	gotest.tools/v3 v3.0.3 // indirect
+	k8s.io/cri-api v0.20.1
 )
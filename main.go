@@ -17,12 +17,78 @@
 package main
 
 import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/l1b0k/volans/cli"
 	"github.com/l1b0k/volans/controller"
+	"github.com/l1b0k/volans/modle"
 	_ "github.com/mattn/go-sqlite3"
 )
 
+var (
+	refreshInterval = flag.Duration("refresh", 10*time.Second, "interval to rescan processes and containers, 0 to rescan only on F5")
+	procRoot        = flag.String("proc-root", modle.DefaultProcRoot, "where procfs of the host is mounted, e.g. /host/proc in a DaemonSet")
+	replay          = flag.String("replay", "", "browse a snapshot file written by the snapshot command instead of this node")
+	dbPath          = flag.String("db", "", "database file recording process count and interface counters at every refresh, empty to keep no history")
+	retention       = flag.Duration("history-retention", 24*time.Hour, "how long samples are kept in -db")
+	compactAfter    = flag.Duration("history-compact-after", time.Hour, "samples older than this are thinned out to one per -history-compact-interval")
+	compactInterval = flag.Duration("history-compact-interval", 5*time.Minute, "interval of samples kept after compaction")
+	procColumns     = flag.String("proc-columns", "", "comma separated columns of the proc pane, e.g. PID,NAME,CPU%,RSS,CMDLINE. Empty for the default ones")
+	runtimeEndpoint = flag.String("runtime-endpoint", "", "container runtime socket, docker.sock, containerd.sock or any CRI socket. Empty to probe default sockets")
+)
+
 func main() {
+	flag.Parse()
+
+	var snapshot *modle.Snapshot
+	if *replay != "" {
+		s, err := modle.ReadSnapshotFile(*replay)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		snapshot = s
+	}
+
+	err := modle.Configure(modle.Config{
+		RuntimeEndpoint: *runtimeEndpoint,
+		ProcRoot:        *procRoot,
+		Replay:          snapshot,
+		DBPath:          *dbPath,
+		History: modle.History{
+			Retention:       *retention,
+			CompactAfter:    *compactAfter,
+			CompactInterval: *compactInterval,
+		},
+	})
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	var columns []string
+	if *procColumns != "" {
+		columns = strings.Split(*procColumns, ",")
+	}
+	if err := controller.Configure(controller.Config{ProcColumns: columns}); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	if flag.NArg() > 0 {
+		if err := cli.Run(flag.Args(), os.Stdout); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	}
+
 	app := controller.GetApp()
+	app.StartRefresh(*refreshInterval)
 
 	if err := app.Run(); err != nil {
 		panic(err)
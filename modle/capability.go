@@ -0,0 +1,82 @@
+/*
+ Copyright 2020  l1b0k
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package modle
+
+import (
+	"strconv"
+)
+
+// capabilityNames is indexed by capability number, as in linux/capability.h
+var capabilityNames = []string{
+	"chown", "dac_override", "dac_read_search", "fowner", "fsetid", "kill", "setgid", "setuid",
+	"setpcap", "linux_immutable", "net_bind_service", "net_broadcast", "net_admin", "net_raw", "ipc_lock", "ipc_owner",
+	"sys_module", "sys_rawio", "sys_chroot", "sys_ptrace", "sys_pacct", "sys_admin", "sys_boot", "sys_nice",
+	"sys_resource", "sys_time", "sys_tty_config", "mknod", "lease", "audit_write", "audit_control", "setfcap",
+	"mac_override", "mac_admin", "syslog", "wake_alarm", "block_suspend", "audit_read", "perfmon", "bpf",
+	"checkpoint_restore",
+}
+
+// capabilitySets is the Cap* keys of /proc/<pid>/status
+var capabilitySets = []string{"CapInh", "CapPrm", "CapEff", "CapBnd", "CapAmb"}
+
+// CapabilitySet is a capability set of a process
+type CapabilitySet struct {
+	Name  string   `json:"name"`
+	Mask  uint64   `json:"mask"`
+	Names []string `json:"names"`
+}
+
+// decodeCapabilities decode the Cap* sets among status lines
+func decodeCapabilities(status []KeyValue) []CapabilitySet {
+	var sets []CapabilitySet
+	for _, name := range capabilitySets {
+		for _, kv := range status {
+			if kv.Key != name {
+				continue
+			}
+			mask, err := strconv.ParseUint(kv.Value, 16, 64)
+			if err != nil {
+				break
+			}
+			sets = append(sets, CapabilitySet{Name: name, Mask: mask, Names: CapabilityNames(mask)})
+			break
+		}
+	}
+	return sets
+}
+
+// CapabilityNames decode a capability mask, unknown bits are named by number such as "cap_41"
+func CapabilityNames(mask uint64) []string {
+	var names []string
+	for i := uint(0); i < 64; i++ {
+		if mask&(1<<i) == 0 {
+			continue
+		}
+		if int(i) < len(capabilityNames) {
+			names = append(names, "cap_"+capabilityNames[i])
+		} else {
+			names = append(names, "cap_"+strconv.Itoa(int(i)))
+		}
+	}
+	return names
+}
+
+// FullCapabilities tell whether mask hold every known capability
+func FullCapabilities(mask uint64) bool {
+	full := uint64(1)<<uint(len(capabilityNames)) - 1
+	return mask&full == full
+}
@@ -0,0 +1,157 @@
+/*
+ Copyright 2020  l1b0k
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package modle
+
+import (
+	"bufio"
+	"io/ioutil"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	// pod uid in kubepods path, cgroupfs driver use dash and systemd driver use underscore
+	// e.g. /kubepods/burstable/pod0b0b0b0b-... or /kubepods.slice/kubepods-burstable.slice/kubepods-burstable-pod0b0b0b0b_....slice
+	podUIDPattern = regexp.MustCompile(`pod([0-9a-f]{8}[-_][0-9a-f]{4}[-_][0-9a-f]{4}[-_][0-9a-f]{4}[-_][0-9a-f]{12})`)
+	// container id as the last element, e.g. /docker/<id>, docker-<id>.scope, cri-containerd-<id>.scope, crio-<id>.scope
+	containerIDPattern = regexp.MustCompile(`^(?:docker-|cri-containerd-|crio-|containerd-)?([0-9a-f]{64})(?:\.scope)?$`)
+)
+
+// CgroupEntry is a line of /proc/<pid>/cgroup
+type CgroupEntry struct {
+	HierarchyID string
+	Controllers string
+	Path        string
+}
+
+// CgroupRoot is where a cgroup namespace is rooted in a hierarchy
+type CgroupRoot struct {
+	HierarchyID int `json:"id"`
+	// Controllers is "(v2)" for the unified hierarchy
+	Controllers string `json:"controllers"`
+	// Root is the namespace root seen from host, Path the cgroup seen inside the namespace
+	Root string `json:"root"`
+	Path string `json:"path"`
+}
+
+// GetCgroupDetail return the cgroup namespace root of each hierarchy
+func (d *Dao) GetCgroupDetail(ns string) []CgroupRoot {
+	var data []CgroupRoot
+	if d.replayTable(ns, tableCgroup, &data) {
+		return data
+	}
+	pids := d.GetPIDs(ns)
+	if len(pids) == 0 {
+		return data
+	}
+	path := d.procPath(pids[0], "cgroup")
+
+	hostView, err := readCgroup(path)
+	if err != nil {
+		return data
+	}
+	var nsView []CgroupEntry
+	err = d.doInNS(pids[0], "cgroup", func() error {
+		var err error
+		nsView, err = readCgroup(path)
+		return err
+	})
+	if err != nil {
+		return data
+	}
+
+	for _, h := range hostView {
+		inner := ""
+		for _, n := range nsView {
+			if n.HierarchyID == h.HierarchyID && n.Controllers == h.Controllers {
+				inner = n.Path
+				break
+			}
+		}
+		root := h.Path
+		if inner != "/" {
+			root = strings.TrimSuffix(h.Path, inner)
+		}
+		if root == "" {
+			root = "/"
+		}
+		controllers := h.Controllers
+		if controllers == "" {
+			controllers = "(v2)"
+		}
+		id, _ := strconv.Atoi(h.HierarchyID)
+		data = append(data, CgroupRoot{HierarchyID: id, Controllers: controllers, Root: root, Path: inner})
+	}
+	return data
+}
+
+// readCgroup parse /proc/<pid>/cgroup, paths are relative to the reader's cgroup namespace
+func readCgroup(path string) ([]CgroupEntry, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var entries []CgroupEntry
+	scanner := bufio.NewScanner(strings.NewReader(string(b)))
+	for scanner.Scan() {
+		parts := strings.SplitN(scanner.Text(), ":", 3)
+		if len(parts) != 3 {
+			continue
+		}
+		entries = append(entries, CgroupEntry{
+			HierarchyID: parts[0],
+			Controllers: parts[1],
+			Path:        parts[2],
+		})
+	}
+	return entries, scanner.Err()
+}
+
+// ParseCgroupPath extract pod uid and container id from a cgroup path of kubepods, docker or systemd slices
+func ParseCgroupPath(p string) (podUID, containerID string) {
+	if m := podUIDPattern.FindStringSubmatch(p); m != nil {
+		podUID = strings.ReplaceAll(m[1], "_", "-")
+	}
+	if m := containerIDPattern.FindStringSubmatch(path.Base(p)); m != nil {
+		containerID = m[1]
+	}
+	return podUID, containerID
+}
+
+// cgroupContainer return pod uid and container id the process belong to, empty if not in a container.
+// Both cgroup v1 hierarchies and the v2 unified hierarchy are checked.
+func cgroupContainer(root string, pid int) (podUID, containerID string) {
+	entries, err := readCgroup(procPath(root, pid, "cgroup"))
+	if err != nil {
+		return "", ""
+	}
+	for _, e := range entries {
+		uid, id := ParseCgroupPath(e.Path)
+		if podUID == "" {
+			podUID = uid
+		}
+		if containerID == "" {
+			containerID = id
+		}
+		if podUID != "" && containerID != "" {
+			break
+		}
+	}
+	return podUID, containerID
+}
@@ -0,0 +1,143 @@
+/*
+ Copyright 2020  l1b0k
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package modle
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	containers "github.com/containerd/containerd/api/services/containers/v1"
+	namespaces "github.com/containerd/containerd/api/services/namespaces/v1"
+	tasks "github.com/containerd/containerd/api/services/tasks/v1"
+	"github.com/containerd/containerd/api/types/task"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// containerdNamespaceHeader is the grpc metadata selecting the containerd namespace of a call
+const containerdNamespaceHeader = "containerd-namespace"
+
+// ContainerdRuntime list running tasks of every containerd namespace through the containerd api,
+// pods and containers of the cri plugin live in namespace k8s.io, docker ones in moby
+type ContainerdRuntime struct {
+	conn       *grpc.ClientConn
+	namespaces namespaces.NamespacesClient
+	containers containers.ContainersClient
+	tasks      tasks.TasksClient
+}
+
+func NewContainerdRuntime(path string) (*ContainerdRuntime, error) {
+	conn, err := dialUnix(path)
+	if err != nil {
+		return nil, err
+	}
+	return &ContainerdRuntime{
+		conn:       conn,
+		namespaces: namespaces.NewNamespacesClient(conn),
+		containers: containers.NewContainersClient(conn),
+		tasks:      tasks.NewTasksClient(conn),
+	}, nil
+}
+
+func (r *ContainerdRuntime) Name() string {
+	return "containerd"
+}
+
+// ListContainers list running containers of every containerd namespace. A namespace failing is skipped,
+// it is an error only when none is listed
+func (r *ContainerdRuntime) ListContainers(ctx context.Context) ([]Container, error) {
+	nss, err := r.namespaces.List(ctx, &namespaces.ListNamespacesRequest{})
+	if err != nil {
+		return nil, err
+	}
+	var result []Container
+	var firstErr error
+	listed := 0
+	for _, ns := range nss.Namespaces {
+		c, err := r.listNamespace(metadata.AppendToOutgoingContext(ctx, containerdNamespaceHeader, ns.Name))
+		if err != nil {
+			// the namespace may be removed meanwhile, containers of the others are still listed
+			if firstErr == nil {
+				firstErr = fmt.Errorf("containerd namespace %s: %w", ns.Name, err)
+			}
+			continue
+		}
+		listed++
+		result = append(result, c...)
+	}
+	if listed == 0 && firstErr != nil {
+		return nil, firstErr
+	}
+	return result, nil
+}
+
+// listNamespace return containers with a running task in the namespace of ctx
+func (r *ContainerdRuntime) listNamespace(ctx context.Context) ([]Container, error) {
+	running, err := r.tasks.List(ctx, &tasks.ListTasksRequest{})
+	if err != nil {
+		return nil, err
+	}
+	pids := map[string]uint32{}
+	for _, t := range running.Tasks {
+		if t.Status == task.StatusRunning && t.Pid > 0 {
+			pids[t.ID] = t.Pid
+		}
+	}
+	if len(pids) == 0 {
+		return nil, nil
+	}
+
+	list, err := r.containers.List(ctx, &containers.ListContainersRequest{})
+	if err != nil {
+		return nil, err
+	}
+	var result []Container
+	for _, s := range list.Containers {
+		pid, ok := pids[s.ID]
+		if !ok {
+			continue
+		}
+		c := Container{
+			ID:           s.ID,
+			Pid:          strconv.Itoa(int(pid)),
+			Name:         s.ID,
+			Image:        s.Image,
+			PodUID:       s.Labels["io.kubernetes.pod.uid"],
+			PodNamespace: s.Labels["io.kubernetes.pod.namespace"],
+			PodName:      s.Labels["io.kubernetes.pod.name"],
+		}
+		switch {
+		case s.Labels["io.cri-containerd.kind"] == "sandbox":
+			c.Type = ContainerTypeSandbox
+		case s.Labels["io.kubernetes.container.name"] != "":
+			c.Type = ContainerTypeContainer
+			c.Name = s.Labels["io.kubernetes.container.name"]
+		case s.Labels["com.docker.compose.service"] != "":
+			c.Type = ContainerTypeCompose
+			c.Name = fmt.Sprintf("%s/%s", s.Labels["com.docker.compose.project"], s.Labels["com.docker.compose.service"])
+		default:
+			c.Type = ContainerTypeContainerd
+		}
+		result = append(result, c)
+	}
+	return result, nil
+}
+
+func (r *ContainerdRuntime) Close() error {
+	return r.conn.Close()
+}
@@ -0,0 +1,203 @@
+/*
+ Copyright 2020  l1b0k
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package modle
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"strconv"
+	"sync"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	cri "k8s.io/cri-api/pkg/apis/runtime/v1"
+)
+
+// criServices are the CRI runtime services tried in order, v1alpha2 is served by runtimes older than kubernetes 1.20.
+// Messages of both versions are the same on the wire, so v1 types are used for both.
+var criServices = []string{
+	"runtime.v1.RuntimeService",
+	"runtime.v1alpha2.RuntimeService",
+}
+
+// CRIRuntime talk to a CRI runtime service on a unix socket, e.g. cri-o or containerd cri plugin
+type CRIRuntime struct {
+	conn *grpc.ClientConn
+
+	// service is the first of criServices the runtime implement, empty until a call find it
+	lock    sync.Mutex
+	service string
+}
+
+// dialUnix connect to a grpc server on the unix socket path. It does not wait for the connection,
+// so a runtime not answering make calls fail instead of blocking startup.
+func dialUnix(path string) (*grpc.ClientConn, error) {
+	return grpc.Dial(path,
+		grpc.WithInsecure(),
+		grpc.WithContextDialer(func(ctx context.Context, addr string) (net.Conn, error) {
+			return (&net.Dialer{}).DialContext(ctx, "unix", addr)
+		}),
+	)
+}
+
+func NewCRIRuntime(path string) (*CRIRuntime, error) {
+	conn, err := dialUnix(path)
+	if err != nil {
+		return nil, err
+	}
+	return &CRIRuntime{conn: conn}, nil
+}
+
+func (r *CRIRuntime) Name() string {
+	return "cri"
+}
+
+// invoke call method of the CRI runtime service
+func (r *CRIRuntime) invoke(ctx context.Context, method string, in, out interface{}) error {
+	service, err := r.findService(ctx)
+	if err != nil {
+		return err
+	}
+	return r.conn.Invoke(ctx, "/"+service+"/"+method, in, out)
+}
+
+// findService return the first of criServices answering Version. It is asked again after a failure,
+// as the runtime may be starting.
+func (r *CRIRuntime) findService(ctx context.Context) (string, error) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	if r.service != "" {
+		return r.service, nil
+	}
+	var err error
+	for _, service := range criServices {
+		err = r.conn.Invoke(ctx, "/"+service+"/Version", &cri.VersionRequest{}, &cri.VersionResponse{})
+		if status.Code(err) == codes.Unimplemented {
+			continue
+		}
+		if err != nil {
+			return "", err
+		}
+		r.service = service
+		return service, nil
+	}
+	return "", err
+}
+
+func (r *CRIRuntime) ListContainers(ctx context.Context) ([]Container, error) {
+	sandboxes := &cri.ListPodSandboxResponse{}
+	err := r.invoke(ctx, "ListPodSandbox", &cri.ListPodSandboxRequest{
+		Filter: &cri.PodSandboxFilter{
+			State: &cri.PodSandboxStateValue{State: cri.PodSandboxState_SANDBOX_READY},
+		},
+	}, sandboxes)
+	if err != nil {
+		return nil, err
+	}
+
+	var result []Container
+	pods := map[string]*cri.PodSandboxMetadata{}
+	for _, s := range sandboxes.Items {
+		if s.Metadata == nil {
+			continue
+		}
+		pods[s.Id] = s.Metadata
+		status := &cri.PodSandboxStatusResponse{}
+		err := r.invoke(ctx, "PodSandboxStatus", &cri.PodSandboxStatusRequest{
+			PodSandboxId: s.Id,
+			Verbose:      true,
+		}, status)
+		if err != nil {
+			continue
+		}
+		pid := infoPid(status.Info)
+		if pid <= 0 {
+			continue
+		}
+		result = append(result, Container{
+			ID:           s.Id,
+			Pid:          strconv.Itoa(pid),
+			PodUID:       s.Metadata.Uid,
+			PodNamespace: s.Metadata.Namespace,
+			PodName:      s.Metadata.Name,
+			Type:         ContainerTypeSandbox,
+		})
+	}
+
+	containers := &cri.ListContainersResponse{}
+	err = r.invoke(ctx, "ListContainers", &cri.ListContainersRequest{
+		Filter: &cri.ContainerFilter{
+			State: &cri.ContainerStateValue{State: cri.ContainerState_CONTAINER_RUNNING},
+		},
+	}, containers)
+	if err != nil {
+		return nil, err
+	}
+	for _, c := range containers.Containers {
+		status := &cri.ContainerStatusResponse{}
+		err := r.invoke(ctx, "ContainerStatus", &cri.ContainerStatusRequest{
+			ContainerId: c.Id,
+			Verbose:     true,
+		}, status)
+		if err != nil {
+			continue
+		}
+		pid := infoPid(status.Info)
+		if pid <= 0 {
+			continue
+		}
+		container := Container{
+			ID:   c.Id,
+			Pid:  strconv.Itoa(pid),
+			Type: ContainerTypeContainer,
+		}
+		if c.Metadata != nil {
+			container.Name = c.Metadata.Name
+		}
+		// status carry the image name, list may only have image id
+		if status.Status != nil && status.Status.Image != nil {
+			container.Image = status.Status.Image.Image
+		} else if c.Image != nil {
+			container.Image = c.Image.Image
+		}
+		if pod, ok := pods[c.PodSandboxId]; ok {
+			container.PodUID = pod.Uid
+			container.PodNamespace = pod.Namespace
+			container.PodName = pod.Name
+		}
+		result = append(result, container)
+	}
+	return result, nil
+}
+
+func (r *CRIRuntime) Close() error {
+	return r.conn.Close()
+}
+
+// infoPid find pid of the sandbox or container from verbose status info,
+// both containerd and cri-o put it in the json under key "info"
+func infoPid(info map[string]string) int {
+	var v struct {
+		Pid int `json:"pid"`
+	}
+	if err := json.Unmarshal([]byte(info["info"]), &v); err != nil {
+		return 0
+	}
+	return v.Pid
+}
@@ -0,0 +1,171 @@
+/*
+ Copyright 2020  l1b0k
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package modle
+
+import (
+	"context"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	cri "k8s.io/cri-api/pkg/apis/runtime/v1"
+	crialpha "k8s.io/cri-api/pkg/apis/runtime/v1alpha2"
+)
+
+// fakeCRI serve one ready pod running one container, the status of container "nopid" has no pid
+type fakeCRI struct {
+	cri.UnimplementedRuntimeServiceServer
+}
+
+func (f *fakeCRI) Version(context.Context, *cri.VersionRequest) (*cri.VersionResponse, error) {
+	return &cri.VersionResponse{RuntimeApiVersion: "v1"}, nil
+}
+
+func (f *fakeCRI) ListPodSandbox(context.Context, *cri.ListPodSandboxRequest) (*cri.ListPodSandboxResponse, error) {
+	return &cri.ListPodSandboxResponse{Items: []*cri.PodSandbox{
+		{Id: "pod1", Metadata: &cri.PodSandboxMetadata{Name: "web", Namespace: "default", Uid: "uid1"}},
+		{Id: "nometa"},
+	}}, nil
+}
+
+func (f *fakeCRI) PodSandboxStatus(_ context.Context, req *cri.PodSandboxStatusRequest) (*cri.PodSandboxStatusResponse, error) {
+	return &cri.PodSandboxStatusResponse{Info: map[string]string{"info": `{"pid":100,"image":"pause"}`}}, nil
+}
+
+func (f *fakeCRI) ListContainers(context.Context, *cri.ListContainersRequest) (*cri.ListContainersResponse, error) {
+	return &cri.ListContainersResponse{Containers: []*cri.Container{
+		{Id: "c1", PodSandboxId: "pod1", Metadata: &cri.ContainerMetadata{Name: "nginx"}, Image: &cri.ImageSpec{Image: "sha256:abc"}},
+		{Id: "c2", PodSandboxId: "gone", Image: &cri.ImageSpec{Image: "sha256:def"}},
+		{Id: "nopid", PodSandboxId: "pod1"},
+	}}, nil
+}
+
+func (f *fakeCRI) ContainerStatus(_ context.Context, req *cri.ContainerStatusRequest) (*cri.ContainerStatusResponse, error) {
+	switch req.ContainerId {
+	case "c1":
+		return &cri.ContainerStatusResponse{
+			Status: &cri.ContainerStatus{Image: &cri.ImageSpec{Image: "nginx:1.19"}},
+			Info:   map[string]string{"info": `{"pid":101}`},
+		}, nil
+	case "c2":
+		return &cri.ContainerStatusResponse{Info: map[string]string{"info": `{"pid":102}`}}, nil
+	}
+	return &cri.ContainerStatusResponse{Info: map[string]string{"info": "not json"}}, nil
+}
+
+// fakeCRIAlpha only serve v1alpha2, with no pod
+type fakeCRIAlpha struct {
+	crialpha.UnimplementedRuntimeServiceServer
+}
+
+func (f *fakeCRIAlpha) Version(context.Context, *crialpha.VersionRequest) (*crialpha.VersionResponse, error) {
+	return &crialpha.VersionResponse{RuntimeApiVersion: "v1alpha2"}, nil
+}
+
+func (f *fakeCRIAlpha) ListPodSandbox(context.Context, *crialpha.ListPodSandboxRequest) (*crialpha.ListPodSandboxResponse, error) {
+	return &crialpha.ListPodSandboxResponse{}, nil
+}
+
+func (f *fakeCRIAlpha) ListContainers(context.Context, *crialpha.ListContainersRequest) (*crialpha.ListContainersResponse, error) {
+	return &crialpha.ListContainersResponse{}, nil
+}
+
+// serveCRI serve register on a unix socket in a temp dir, and return a CRIRuntime connected to it
+func serveCRI(t *testing.T, register func(*grpc.Server)) *CRIRuntime {
+	dir, err := ioutil.TempDir("", "volans-cri")
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, "cri.sock")
+	l, err := net.Listen("unix", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := grpc.NewServer()
+	register(s)
+	go func() { _ = s.Serve(l) }()
+
+	r, err := NewCRIRuntime(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		_ = r.Close()
+		s.Stop()
+		_ = os.RemoveAll(dir)
+	})
+	return r
+}
+
+func TestCRIRuntimeListContainers(t *testing.T) {
+	r := serveCRI(t, func(s *grpc.Server) { cri.RegisterRuntimeServiceServer(s, &fakeCRI{}) })
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	got, err := r.ListContainers(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Container{
+		{ID: "pod1", Pid: "100", PodUID: "uid1", PodNamespace: "default", PodName: "web", Type: ContainerTypeSandbox},
+		{ID: "c1", Pid: "101", Name: "nginx", Image: "nginx:1.19", PodUID: "uid1", PodNamespace: "default", PodName: "web", Type: ContainerTypeContainer},
+		{ID: "c2", Pid: "102", Image: "sha256:def", Type: ContainerTypeContainer},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ListContainers() = %+v, want %+v", got, want)
+	}
+	if r.service != "runtime.v1.RuntimeService" {
+		t.Errorf("service = %s, want runtime.v1.RuntimeService", r.service)
+	}
+}
+
+func TestCRIRuntimeFallbackV1alpha2(t *testing.T) {
+	r := serveCRI(t, func(s *grpc.Server) { crialpha.RegisterRuntimeServiceServer(s, &fakeCRIAlpha{}) })
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	got, err := r.ListContainers(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 0 {
+		t.Errorf("ListContainers() = %+v, want none", got)
+	}
+	if r.service != "runtime.v1alpha2.RuntimeService" {
+		t.Errorf("service = %s, want runtime.v1alpha2.RuntimeService", r.service)
+	}
+}
+
+func TestInfoPid(t *testing.T) {
+	tests := []struct {
+		info map[string]string
+		want int
+	}{
+		{map[string]string{"info": `{"pid":42,"sandboxID":"x"}`}, 42},
+		{map[string]string{"info": `{"sandboxID":"x"}`}, 0},
+		{map[string]string{"info": "not json"}, 0},
+		{nil, 0},
+	}
+	for _, tt := range tests {
+		if got := infoPid(tt.info); got != tt.want {
+			t.Errorf("infoPid(%v) = %d, want %d", tt.info, got, tt.want)
+		}
+	}
+}
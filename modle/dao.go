@@ -18,7 +18,6 @@ package modle
 
 import (
 	"context"
-	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -29,24 +28,23 @@ import (
 	"time"
 
 	"github.com/c9s/goprocinfo/linux"
-	netns "github.com/containernetworking/plugins/pkg/ns"
-	"github.com/docker/docker/api/types"
-	docker "github.com/docker/docker/client"
 	"github.com/safchain/ethtool"
-	"github.com/shirou/gopsutil/process"
 	"github.com/vishvananda/netlink"
 	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
 	"gorm.io/gorm/logger"
 )
 
-// TODO
-var supportedNS = []string{"net"}
+// supportedNS is the namespace types listed under /proc/<pid>/ns
+var supportedNS = []string{"cgroup", "ipc", "mnt", "net", "pid", "time", "user", "uts"}
 
 type Proc struct {
 	Pid       string `gorm:"primaryKey;column:pid;index:idx_pid"`
-	Namespace string `gorm:"column:namespace"`
-	NSType    string `gorm:"column:ns_type"`
+	NSType    string `gorm:"primaryKey;column:ns_type"`
+	Namespace string `gorm:"column:namespace;index:idx_namespace"`
+	// parsed from /proc/<pid>/cgroup, empty when not in a container
+	PodUID      string `gorm:"column:pod_uid"`
+	ContainerID string `gorm:"column:container_id"`
 }
 
 // TableName overrides the table name
@@ -54,11 +52,24 @@ func (Proc) TableName() string {
 	return "proc"
 }
 
+// Container.Type values
+const (
+	ContainerTypeSandbox    = "podsandbox" // kubernetes pod sandbox, hold the pod namespaces
+	ContainerTypeContainer  = "container"  // kubernetes app container
+	ContainerTypeDocker     = "docker"     // plain docker container
+	ContainerTypeCompose    = "compose"    // docker compose service
+	ContainerTypeContainerd = "containerd" // plain containerd container
+)
+
 type Container struct {
-	Pid          string `gorm:"primaryKey,column:pid"`
+	ID           string `gorm:"primaryKey;column:id"`
+	Pid          string `gorm:"column:pid;index:idx_container_pid"`
+	Name         string `gorm:"column:name"`
+	Image        string `gorm:"column:image"`
+	PodUID       string `gorm:"column:pod_uid;index:idx_pod_uid"`
 	PodNamespace string `gorm:"column:pod_namespace"`
 	PodName      string `gorm:"column:pod_name"`
-	Type         string `gorm:"column:type"` // io.kubernetes.docker.type podsandbox/container
+	Type         string `gorm:"column:type"`
 }
 
 // TableName overrides the table name
@@ -66,6 +77,7 @@ func (Container) TableName() string {
 	return "container"
 }
 
+// initDB open the in-memory database indexing pids and containers of this run
 func initDB() *gorm.DB {
 	db, err := gorm.Open(sqlite.Open("file::memory:?cache=shared"), &gorm.Config{Logger: logger.Discard})
 	if err != nil {
@@ -75,12 +87,86 @@ func initDB() *gorm.DB {
 	if err != nil {
 		panic("failed to migrate database")
 	}
+	// refresh write from another goroutine, a single connection avoid table locked error of shared cache
+	sqlDB, err := db.DB()
+	if err != nil {
+		panic(err)
+	}
+	sqlDB.SetMaxOpenConns(1)
 	return db
 }
 
+// Config of Dao, set by Configure before the first GetDao
+type Config struct {
+	// RuntimeEndpoint is the container runtime socket, empty to probe default sockets
+	RuntimeEndpoint string
+	// ProcRoot is where procfs of the inspected host is mounted, DefaultProcRoot if empty
+	ProcRoot string
+	// Replay is the snapshot shown instead of the live system, nil to read the live system
+	Replay *Snapshot
+	// DBPath is the on-disk database recording samples at every refresh, empty to keep no history
+	DBPath  string
+	History History
+}
+
+var config Config
+
+// configuredRuntime is created from Config.RuntimeEndpoint by Configure
+var configuredRuntime Runtime
+
+// configuredReplay is the Dao of Config.Replay, loaded by Configure
+var configuredReplay *Dao
+
+// Configure set config used by GetDao, it has no effect once Dao created.
+// An explicit RuntimeEndpoint which is not usable is an error, rather than showing no pods,
+// and so is a Replay snapshot which can not be loaded.
+func Configure(c Config) error {
+	config = c
+	configuredRuntime = nil
+	configuredReplay = nil
+	if c.Replay != nil {
+		d := &Dao{DB: initDB()}
+		if err := d.loadSnapshot(c.Replay); err != nil {
+			return fmt.Errorf("load snapshot: %w", err)
+		}
+		configuredReplay = d
+		return nil
+	}
+	if c.RuntimeEndpoint == "" {
+		return nil
+	}
+	runtime, err := NewRuntime(c.RuntimeEndpoint)
+	if err != nil {
+		return fmt.Errorf("runtime endpoint %s: %w", c.RuntimeEndpoint, err)
+	}
+	configuredRuntime = runtime
+	return nil
+}
+
 type Dao struct {
-	DB           *gorm.DB
-	DockerClient *docker.Client
+	DB      *gorm.DB
+	Runtime Runtime
+	// historyDB is the on-disk database of samples, shared by every run using the same file.
+	// nil when samples are not recorded
+	historyDB *gorm.DB
+	// ProcRoot is where procfs is read from
+	ProcRoot string
+
+	// netRates is the latest /proc/net/dev sample of each interface, used for rates shown on screen
+	netRates netRates
+	// latest cpu time of each process for CPU%
+	sampleLock  sync.Mutex
+	procSamples map[int]procSample
+	// ethtoolSamples hold driver counters of interfaces shown in detail, keyed by ns/name
+	ethtoolSamples map[string]ethtoolSample
+
+	bootOnce sync.Once
+	boot     time.Time
+
+	// snapshot being replayed, nil for the live system
+	snapshot *Snapshot
+	// history is nil when samples are not recorded
+	history *History
 }
 
 var dao *Dao
@@ -88,18 +174,32 @@ var once sync.Once
 
 func GetDao() *Dao {
 	once.Do(func() {
-		db := initDB()
+		if configuredReplay != nil {
+			dao = configuredReplay
+			return
+		}
 
-		client, err := docker.NewClientWithOpts(
-			docker.WithVersion("v1.21"),
-		)
-		if err != nil {
-			panic(err)
+		// an explicit endpoint is created by Configure. Probing may find no runtime,
+		// namespaces are still listed but without pod info
+		runtime := configuredRuntime
+		if runtime == nil && config.RuntimeEndpoint == "" {
+			runtime, _ = NewRuntime("")
+		}
+
+		procRoot := config.ProcRoot
+		if procRoot == "" {
+			procRoot = DefaultProcRoot
 		}
 
 		dao = &Dao{
-			DB:           db,
-			DockerClient: client,
+			DB:       initDB(),
+			Runtime:  runtime,
+			ProcRoot: procRoot,
+		}
+		if config.DBPath != "" {
+			history := config.History
+			dao.history = &history
+			dao.historyDB = initHistoryDB(config.DBPath)
 		}
 		dao.Run()
 		dao.LoadProcData()
@@ -108,98 +208,108 @@ func GetDao() *Dao {
 }
 
 func (d *Dao) LoadProcData() {
-	pids, err := process.Pids()
+	if d.snapshot != nil {
+		return
+	}
+	pids, err := ListPids(d.ProcRoot)
 	if err != nil {
 		return
 	}
+	alive := make(map[string]bool, len(pids))
+	for _, id := range pids {
+		alive[strconv.Itoa(int(id))] = true
+	}
+
+	// known pid -> ns_type -> namespace already indexed
+	known := map[string]map[string]string{}
 	var procs []Proc
 	result := d.DB.Find(&procs)
 	if result.Error == nil {
 		for _, proc := range procs {
-			ok := false
-			for _, id := range pids {
-				if strconv.Itoa(int(id)) == proc.Pid {
-					ok = true
-					break
-				}
-			}
-			if !ok {
+			if !alive[proc.Pid] {
 				// delete
 				d.DB.Delete(&proc)
+				continue
+			}
+			if known[proc.Pid] == nil {
+				known[proc.Pid] = map[string]string{}
 			}
+			known[proc.Pid][proc.NSType] = proc.Namespace
 		}
 	}
-	// create
+	// create or replace. Namespaces are read again, the pid may be reused or the process may enter another namespace
 	for _, id := range pids {
-		ok := false
-		for _, proc := range procs {
-			if strconv.Itoa(int(id)) == proc.Pid {
-				ok = true
-				break
-			}
-		}
-		if ok {
-			continue
-		}
+		pid := strconv.Itoa(int(id))
+		current := map[string]string{}
 		for _, nsType := range supportedNS {
-			inode, err := GetNSByPid(id, nsType)
+			inode, err := GetNSByPid(d.ProcRoot, id, nsType)
 			if err != nil {
+				// kernel may not support this type
 				continue
 			}
+			current[nsType] = inode
+		}
+		if sameNamespaces(known[pid], current) {
+			continue
+		}
+		if known[pid] != nil {
+			d.DB.Where("pid = ?", pid).Delete(&Proc{})
+		}
+		podUID, containerID := cgroupContainer(d.ProcRoot, int(id))
+		for nsType, inode := range current {
 			p := Proc{
-				Pid:       strconv.Itoa(int(id)),
-				Namespace: inode,
-				NSType:    nsType,
+				Pid:         pid,
+				NSType:      nsType,
+				Namespace:   inode,
+				PodUID:      podUID,
+				ContainerID: containerID,
 			}
 
 			d.DB.Create(&p)
 		}
 	}
+}
 
+// sameNamespaces tell whether a and b map the same ns types to the same namespaces
+func sameNamespaces(a, b map[string]string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for nsType, inode := range a {
+		if b[nsType] != inode {
+			return false
+		}
+	}
+	return true
 }
 
+// Run replace containers with the ones running in runtime, old data is kept if runtime fail
 func (d *Dao) Run() {
-	if d.DockerClient == nil {
+	if d.snapshot != nil || d.Runtime == nil {
 		return
 	}
-	ctx, _ := context.WithTimeout(context.Background(), 10*time.Second)
-	containers, err := d.DockerClient.ContainerList(ctx, types.ContainerListOptions{})
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	containers, err := d.Runtime.ListContainers(ctx)
 	if err != nil {
 		return
 	}
 
-	for _, s := range containers {
-		state, err := d.DockerClient.ContainerInspect(ctx, s.ID)
+	_ = d.DB.Transaction(func(tx *gorm.DB) error {
+		err := tx.Where("1 = 1").Delete(&Container{}).Error
 		if err != nil {
-			continue
+			return err
 		}
-		// only care sandbox
-		if s.Labels["io.kubernetes.docker.type"] != "podsandbox" {
-			continue
+		if len(containers) == 0 {
+			return nil
 		}
-
-		var c Container
-		result := d.DB.Where("pod_namespace = ? and pod_name = ?", s.Labels["io.kubernetes.pod.namespace"], s.Labels["io.kubernetes.pod.name"]).First(&c)
-		if result.Error != nil {
-			if errors.Is(result.Error, gorm.ErrRecordNotFound) {
-				d.DB.Create(&Container{
-					Pid:          strconv.Itoa(state.State.Pid),
-					PodNamespace: s.Labels["io.kubernetes.pod.namespace"],
-					PodName:      s.Labels["io.kubernetes.pod.name"],
-					Type:         s.Labels["io.kubernetes.docker.type"],
-				})
-			}
-		} else {
-			// update pid only
-			c.Pid = strconv.Itoa(state.State.Pid)
-			d.DB.Save(c)
-		}
-	}
+		return tx.Create(&containers).Error
+	})
 }
 
 func (d *Dao) GetPIDs(ns string) []int {
 	var pids []int
-	rows, err := d.DB.Raw("select pid from proc where namespace=?", ns).Rows()
+	rows, err := d.DB.Raw("select distinct pid from proc where namespace=?", ns).Rows()
 	if err != nil {
 		//TODO log
 		return pids
@@ -211,9 +321,11 @@ func (d *Dao) GetPIDs(ns string) []int {
 		if err != nil {
 			panic(err)
 		}
-		_, err = os.Stat(filepath.Join("/proc", pid))
-		if err != nil {
-			continue
+		if d.snapshot == nil {
+			_, err = os.Stat(filepath.Join(d.ProcRoot, pid))
+			if err != nil {
+				continue
+			}
 		}
 		p, _ := strconv.Atoi(pid)
 		pids = append(pids, p)
@@ -222,163 +334,322 @@ func (d *Dao) GetPIDs(ns string) []int {
 	return pids
 }
 
-func (d *Dao) GetNSWithPidCount() [][]string {
-	var data [][]string
-	var containers []Container
-	d.DB.Model(&Container{}).Find(&containers)
+// GetNSType return the type of namespace ns, empty if it is unknown
+func (d *Dao) GetNSType(ns string) string {
+	var p Proc
+	if err := d.DB.Where("namespace = ?", ns).First(&p).Error; err != nil {
+		return ""
+	}
+	return p.NSType
+}
 
-	rows, err := d.DB.Raw("select namespace, ns_type, count(*) as count from proc group by namespace,ns_type").Rows()
-	if err != nil {
+// GetNSWithPidCount return all namespaces with the number of processes, pods and containers in each
+func (d *Dao) GetNSWithPidCount() []Namespace {
+	var data []Namespace
+	type nsCount struct {
+		Namespace string
+		NSType    string
+		Count     int
+	}
+	// read all rows before next query, db has only one connection
+	var counts []nsCount
+	result := d.DB.Raw("select namespace, ns_type, count(*) as count from proc group by namespace, ns_type order by ns_type, namespace").Scan(&counts)
+	if result.Error != nil {
 		//TODO log
 		return data
 	}
-	defer rows.Close()
-	for rows.Next() {
-		var ns string
-		var nsType string
-		var count int
-		err := rows.Scan(&ns, &nsType, &count)
-		if err != nil {
-			panic(err)
+	pods := map[string][]string{}
+	names := map[string][]string{}
+	add := func(ns, pod, name string) {
+		if pod != "" && !containsString(pods[ns], pod) {
+			pods[ns] = append(pods[ns], pod)
+		}
+		if name != "" && !containsString(names[ns], name) {
+			names[ns] = append(names[ns], name)
 		}
+	}
 
-		podInfo := map[string]interface{}{}
-		d.DB.Raw("select b.pod_namespace as pod_namespace, b.pod_name as pod_name from"+
-			" proc as a , container as b where a.pid = b.pid and a.namespace = ?", ns).First(&podInfo)
-		data = append(data, []string{ns, nsType, strconv.Itoa(count), fmt.Sprintf("%s/%s", podInfo["pod_namespace"], podInfo["pod_name"])})
+	// processes attributed by cgroup, work without runtime
+	podNames, containers := d.containerIndex()
+	type nsCgroup struct {
+		Namespace   string
+		PodUID      string
+		ContainerID string
+	}
+	var cgroups []nsCgroup
+	d.DB.Raw("select distinct namespace, pod_uid, container_id from proc" +
+		" where pod_uid != '' or container_id != '' order by pod_uid, container_id").Scan(&cgroups)
+	for _, c := range cgroups {
+		add(c.Namespace, podLabel(podNames, c.PodUID), containerLabel(containers, c.ContainerID))
 	}
-	return data
-}
 
-func (d *Dao) GetNetNSDetail(ns string) [][]string {
-	var data [][]string
-	pids := d.GetPIDs(ns)
+	// init process of containers reported by runtime
+	type nsContainer struct {
+		Namespace    string
+		Name         string
+		PodNamespace string
+		PodName      string
+		Type         string
+	}
+	var runtimeContainers []nsContainer
+	d.DB.Raw("select distinct a.namespace as namespace, b.name as name, b.pod_namespace as pod_namespace," +
+		" b.pod_name as pod_name, b.type as type from proc as a, container as b where a.pid = b.pid" +
+		" order by b.pod_namespace, b.pod_name, b.name").Scan(&runtimeContainers)
+	for _, c := range runtimeContainers {
+		pod, name := "", c.Name
+		if c.PodName != "" {
+			pod = fmt.Sprintf("%s/%s", c.PodNamespace, c.PodName)
+		}
+		if c.Type == ContainerTypeSandbox {
+			name = ""
+		}
+		add(c.Namespace, pod, name)
+	}
 
-	netNS, err := netns.GetNS(fmt.Sprintf("/proc/%d/ns/net", pids[0]))
-	if err != nil {
-		return data
+	for _, c := range counts {
+		data = append(data, Namespace{
+			Inode:      c.Namespace,
+			Type:       c.NSType,
+			NProcs:     c.Count,
+			Pods:       pods[c.Namespace],
+			Containers: names[c.Namespace],
+		})
 	}
-	defer netNS.Close()
+	return data
+}
 
-	tool, err := ethtool.NewEthtool()
-	if err != nil {
-		return nil
+// GetNetNSDetail return interfaces of net namespace ns, links of netlink merged with counters from /proc/net/dev.
+// An interface known by only one of them is still returned.
+func (d *Dao) GetNetNSDetail(ns string) ([]Interface, error) {
+	if d.snapshot != nil {
+		return d.snapshot.Interfaces[ns], nil
 	}
-	defer tool.Close()
+	return d.netNSDetail(ns, &d.netRates)
+}
 
-	networkStats, err := linux.ReadNetworkStat(fmt.Sprintf("/proc/%d/net/dev", pids[0]))
-	if err != nil {
-		//TODO log
-		return data
+// netNSDetail read interfaces of ns, rates are against the previous sample in rates, or left out if rates is nil
+func (d *Dao) netNSDetail(ns string, rates *netRates) ([]Interface, error) {
+	pids := d.GetPIDs(ns)
+	if len(pids) == 0 {
+		return nil, fmt.Errorf("no process in namespace %s", ns)
 	}
 
-	var links []netlink.Link
-	err = netNS.Do(func(ns netns.NetNS) error {
-		var err error
-		links, err = netlink.LinkList()
-		if err != nil {
-			return err
+	// interfaces in /proc/net/dev are still shown when netlink fail, and the other way around
+	networkStats, statErr := linux.ReadNetworkStat(d.procPath(pids[0], "net", "dev"))
+	now := time.Now()
+
+	var data []Interface
+	var veths []vethLink
+	var ids map[int]string
+	err := d.doInNS(pids[0], "net", func() error {
+		links, linkErr := netlink.LinkList()
+		if linkErr != nil && statErr != nil {
+			return fmt.Errorf("list links: %v, read /proc/net/dev: %v", linkErr, statErr)
+		}
+		names := make(map[int]string, len(links))
+		for _, l := range links {
+			names[l.Attrs().Index] = l.Attrs().Name
+		}
+
+		// ethtool ioctls act on the net namespace of the socket, so it is opened in ns
+		tool, toolErr := ethtool.NewEthtool()
+		if toolErr == nil {
+			defer tool.Close()
 		}
 
-		for _, stat := range networkStats {
-			i := 0
-			for ; i < len(links); i++ {
-				if links[i].Attrs().Name == stat.Iface {
-					break
+		for _, dev := range mergeNetDevices(links, networkStats) {
+			stat := dev.Stat
+			iface := Interface{
+				Name:      stat.Iface,
+				Type:      "?",
+				RxBytes:   stat.RxBytes,
+				TxBytes:   stat.TxBytes,
+				RxPackets: stat.RxPackets,
+				TxPackets: stat.TxPackets,
+				RxErrs:    stat.RxErrs,
+				RxDrop:    stat.RxDrop,
+				TxErrs:    stat.TxErrs,
+				TxDrop:    stat.TxDrop,
+			}
+			if rates != nil {
+				if rate, ok := rates.rate(ns, stat, now); ok {
+					iface.Rate = &rate
 				}
 			}
-			var s []string
-			addrs, err := netlink.AddrList(links[i], netlink.FAMILY_ALL)
-			if err == nil {
-				for _, a := range addrs {
-					if a.IP.IsLinkLocalUnicast() {
-						continue
+			if l := dev.Link; l != nil {
+				attrs := l.Attrs()
+				iface.Index = attrs.Index
+				iface.Type = l.Type()
+				iface.MAC = attrs.HardwareAddr.String()
+				iface.MTU = attrs.MTU
+				if attrs.Flags != 0 {
+					iface.Flags = strings.Split(attrs.Flags.String(), "|")
+				}
+				iface.Details = linkDetails(l, names)
+				if addrs, err := netlink.AddrList(l, netlink.FAMILY_ALL); err == nil {
+					for _, a := range addrs {
+						if a.IP.IsLinkLocalUnicast() {
+							continue
+						}
+						iface.IPs = append(iface.IPs, a.IP.String())
 					}
-					s = append(s, a.IP.String())
+				}
+				if l.Type() == "veth" {
+					veths = append(veths, vethLink{Name: attrs.Name, ParentIndex: attrs.ParentIndex, NetNsID: attrs.NetNsID})
 				}
 			}
-			channelStr := ""
-			channel, err := tool.GetChannels(stat.Iface)
-			if err == nil {
-				channelStr = fmt.Sprintf("%d/%d", channel.CombinedCount, channel.MaxCombined)
-			}
-
-			feature, _ := tool.Features(stat.Iface)
-			data = append(data, []string{
-				stat.Iface,
-				links[i].Type(),
-				links[i].Attrs().HardwareAddr.String(),
-				channelStr,
-				strings.Join(s, ","),
-				strconv.FormatUint(stat.RxErrs, 10),
-				strconv.FormatUint(stat.RxDrop, 10),
-				strconv.FormatUint(stat.TxErrs, 10),
-				strconv.FormatUint(stat.TxDrop, 10),
-				strconv.Itoa(links[i].Attrs().MTU),
-				links[i].Attrs().Flags.String(),
-				formatBool(feature["tx-generic-segmentation"]), // see https://kernel.googlesource.com/pub/scm/network/ethtool/ethtool/+/v3.4.2/ethtool.c#140
-				formatBool(feature["tx-tcp-segmentation"]),
-				formatBool(feature["rx-lro"]),
-				formatBool(feature["rx-gro"]),
-				formatBool(feature["tx-scatter-gather"]),
-				fmt.Sprintf("%s/%s", formatBool(feature["rx-checksum"]), formatBool(feature["tx-checksum-ip-generic"])),
-			})
+			if toolErr == nil {
+				if channel, err := tool.GetChannels(stat.Iface); err == nil {
+					iface.Channels = &Channels{
+						Combined:    channel.CombinedCount,
+						MaxCombined: channel.MaxCombined,
+					}
+				}
 
+				// see https://kernel.googlesource.com/pub/scm/network/ethtool/ethtool/+/v3.4.2/ethtool.c#140
+				feature, _ := tool.Features(stat.Iface)
+				iface.GSO = feature["tx-generic-segmentation"]
+				iface.TSO = feature["tx-tcp-segmentation"]
+				iface.LRO = feature["rx-lro"]
+				iface.GRO = feature["rx-gro"]
+				iface.SG = feature["tx-scatter-gather"]
+				iface.RxChecksum = feature["rx-checksum"]
+				iface.TxChecksum = feature["tx-checksum-ip-generic"]
+			}
+			data = append(data, iface)
+		}
+		// nsids held by veths are asked in ns, they are relative to it
+		want := map[int]bool{}
+		for _, v := range veths {
+			if v.NetNsID >= 0 {
+				want[v.NetNsID] = true
+			}
+		}
+		if len(want) > 0 {
+			files := d.openNetNS()
+			ids = files.ids(want)
+			files.Close()
 		}
-
 		return nil
 	})
 	if err != nil {
-		return data
+		return nil, err
 	}
 
-	return data
+	peers := d.vethPeers(ns, veths, ids)
+	for i := range data {
+		data[i].Peer = peers[data[i].Name]
+	}
+	return data, nil
 }
 
-func (d *Dao) GetProcDetail(ns string) [][]string {
-	var data [][]string
+// GetProcDetail return processes in namespace ns
+func (d *Dao) GetProcDetail(ns string) []Process {
+	var data []Process
+	if d.snapshot != nil {
+		pids := map[int]bool{}
+		for _, pid := range d.GetPIDs(ns) {
+			pids[pid] = true
+		}
+		for _, p := range d.snapshot.Processes {
+			if pids[p.Pid] {
+				data = append(data, p)
+			}
+		}
+		return data
+	}
+	pods, containers := d.containerIndex()
 	pids := d.GetPIDs(ns)
+	now := time.Now()
 	for _, pid := range pids {
-		p, err := linux.ReadProcess(uint64(pid), "/proc")
+		p, err := linux.ReadProcess(uint64(pid), d.ProcRoot)
 		if err != nil {
 			continue
 		}
-		cmd := p.Cmdline
-		if len(p.Cmdline) > 20 {
-			cmd = p.Cmdline[:20]
+		podUID, containerID := cgroupContainer(d.ProcRoot, pid)
+		proc := Process{
+			Pid:       pid,
+			PPid:      int(p.Status.PPid),
+			Name:      p.Status.Name,
+			State:     p.Stat.State,
+			Pod:       podLabel(pods, podUID),
+			Container: containerLabel(containers, containerID),
+			Cmdline:   p.Cmdline,
+			StartTime: p.Stat.Starttime,
+			RSS:       p.Status.VmRSS * 1024,
+			VSZ:       p.Status.VmSize * 1024,
+			Threads:   int(p.Status.Threads),
+			FDs:       countFDs(d.ProcRoot, pid),
+			UID:       int(p.Status.RealUid),
+			User:      userName(p.Status.RealUid),
+			Nice:      int(p.Stat.Nice),
+			Priority:  int(p.Stat.Priority),
+			LastCPU:   int(p.Stat.Processor),
+			NUMANode:  numaNode(int(p.Stat.Processor)),
 		}
-
-		data = append(data, []string{
-			strconv.Itoa(pid),
-			p.Status.Name,
-			p.Stat.State,
-			formatStr(p.Status.CpusAllowed),
-			cmd,
-		})
+		proc.CpusAllowedList, proc.MemsAllowedList, _ = readAllowedLists(d.procPath(pid, "status"))
+		if percent, ok := d.cpuPercent(pid, &p.Stat, now); ok {
+			proc.CPUPercent = &percent
+		}
+		if boot := d.bootTime(); !boot.IsZero() {
+			proc.StartedAt = boot.Add(time.Duration(p.Stat.Starttime) * time.Second / clockTicks)
+		}
+		data = append(data, proc)
 	}
 
 	return data
 }
 
-// ugly...
-func formatStr(b []uint32) string {
-	var ss []string
-	for _, i := range b {
-		ss = append(ss, fmt.Sprintf("%b", i))
+// containerIndex map pod uid to namespace/name, and container id to container, from runtime data
+func (d *Dao) containerIndex() (map[string]string, map[string]Container) {
+	pods := map[string]string{}
+	containers := map[string]Container{}
+	var all []Container
+	d.DB.Find(&all)
+	for _, c := range all {
+		containers[c.ID] = c
+		if c.PodUID != "" && c.PodName != "" {
+			pods[c.PodUID] = fmt.Sprintf("%s/%s", c.PodNamespace, c.PodName)
+		}
 	}
-	return strings.Join(ss, " ")
+	return pods, containers
 }
 
-func formatBool(b bool) string {
-	if b {
-		return "on"
+// podLabel show pod as namespace/name when runtime know it, or uid
+func podLabel(pods map[string]string, uid string) string {
+	if name, ok := pods[uid]; ok {
+		return name
+	}
+	return uid
+}
+
+// containerLabel show container name when runtime know it, or the short id
+func containerLabel(containers map[string]Container, id string) string {
+	c, ok := containers[id]
+	if ok && c.Type == ContainerTypeSandbox {
+		return ""
+	}
+	if ok && c.Name != "" {
+		return c.Name
+	}
+	if len(id) > 12 {
+		return id[:12]
+	}
+	return id
+}
+
+func containsString(ss []string, s string) bool {
+	for _, v := range ss {
+		if v == s {
+			return true
+		}
 	}
-	return "off"
+	return false
 }
 
-// GetNSByPid get namespace inode id by pid and namespace type
-func GetNSByPid(pid int32, nsType string) (string, error) {
-	info, err := os.Readlink(fmt.Sprintf("/proc/%d/ns/%s", pid, nsType))
+// GetNSByPid get namespace inode id by pid and namespace type from procfs mounted at root
+func GetNSByPid(root string, pid int32, nsType string) (string, error) {
+	info, err := os.Readlink(procPath(root, int(pid), "ns", nsType))
 	if err != nil {
 		return "", err
 	}
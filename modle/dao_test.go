@@ -0,0 +1,68 @@
+/*
+ Copyright 2020  l1b0k
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package modle
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// fakeNS point /proc/<pid>/ns/<type> under root to the given inodes, types left out are removed
+func fakeNS(t *testing.T, root string, pid string, inodes map[string]string) {
+	dir := filepath.Join(root, pid, "ns")
+	if err := os.RemoveAll(dir); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	for nsType, inode := range inodes {
+		if err := os.Symlink(nsType+":["+inode+"]", filepath.Join(dir, nsType)); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestLoadProcDataReusedPid(t *testing.T) {
+	root, err := ioutil.TempDir("", "proc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(root)
+
+	d := &Dao{DB: initDB(), ProcRoot: root}
+	t.Cleanup(func() { d.DB.Where("1 = 1").Delete(&Proc{}) })
+
+	// no ns/time, as on kernels before 5.6
+	fakeNS(t, root, "42", map[string]string{"net": "100", "uts": "200"})
+	d.LoadProcData()
+	if pids := d.GetPIDs("100"); !reflect.DeepEqual(pids, []int{42}) {
+		t.Fatalf("pids of net 100 = %v, want [42]", pids)
+	}
+
+	// pid reused by a process in another net namespace, and without uts
+	fakeNS(t, root, "42", map[string]string{"net": "101"})
+	d.LoadProcData()
+	for ns, want := range map[string][]int{"100": nil, "101": {42}, "200": nil} {
+		if pids := d.GetPIDs(ns); !reflect.DeepEqual(pids, want) {
+			t.Errorf("pids of %s = %v, want %v", ns, pids, want)
+		}
+	}
+}
@@ -0,0 +1,245 @@
+/*
+ Copyright 2020  l1b0k
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package modle
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// kinds of Change
+const (
+	ChangeNamespace = "namespace"
+	ChangeProcess   = "process"
+	ChangeInterface = "interface"
+)
+
+// Change is a difference found between two snapshots
+type Change struct {
+	Kind string `json:"kind"`
+	// Action is created, destroyed, moved, added, removed, changed or counters
+	Action string `json:"action"`
+	// Object is what changed, such as "net 4026531840", "pid 1 systemd" or "4026531840/eth0"
+	Object string `json:"object"`
+	Detail string `json:"detail"`
+}
+
+// Diff report what changed from snapshot a to b: namespaces created or destroyed, processes moved to
+// another namespace, interfaces added or removed, their mtu, flags and offloads, and counter deltas
+func Diff(a, b *Snapshot) []Change {
+	var changes []Change
+	changes = append(changes, diffNamespaces(a, b)...)
+	changes = append(changes, diffProcesses(a, b)...)
+	changes = append(changes, diffInterfaces(a, b)...)
+	return changes
+}
+
+func diffNamespaces(a, b *Snapshot) []Change {
+	count := func(s *Snapshot) map[string]int {
+		m := map[string]int{}
+		for _, p := range s.Procs {
+			m[p.NSType+" "+p.Namespace]++
+		}
+		return m
+	}
+	before, after := count(a), count(b)
+
+	var changes []Change
+	for _, key := range sortedKeys(after) {
+		if _, ok := before[key]; !ok {
+			changes = append(changes, Change{ChangeNamespace, "created", key, fmt.Sprintf("nprocs %d", after[key])})
+		}
+	}
+	for _, key := range sortedKeys(before) {
+		if _, ok := after[key]; !ok {
+			changes = append(changes, Change{ChangeNamespace, "destroyed", key, fmt.Sprintf("nprocs %d", before[key])})
+		}
+	}
+	return changes
+}
+
+func diffProcesses(a, b *Snapshot) []Change {
+	index := func(s *Snapshot) map[string]map[string]string {
+		m := map[string]map[string]string{}
+		for _, p := range s.Procs {
+			if m[p.Pid] == nil {
+				m[p.Pid] = map[string]string{}
+			}
+			m[p.Pid][p.NSType] = p.Namespace
+		}
+		return m
+	}
+	processes := func(s *Snapshot) map[string]Process {
+		m := map[string]Process{}
+		for _, p := range s.Processes {
+			m[strconv.Itoa(p.Pid)] = p
+		}
+		return m
+	}
+	before, after := index(a), index(b)
+	beforeProc, afterProc := processes(a), processes(b)
+
+	var pids []int
+	for pid := range after {
+		if id, err := strconv.Atoi(pid); err == nil {
+			pids = append(pids, id)
+		}
+	}
+	sort.Ints(pids)
+
+	var changes []Change
+	for _, id := range pids {
+		pid := strconv.Itoa(id)
+		old, ok := before[pid]
+		if !ok {
+			continue
+		}
+		// pid reused by another process
+		if p, ok := beforeProc[pid]; ok && p.StartTime != afterProc[pid].StartTime {
+			continue
+		}
+		for _, nsType := range supportedNS {
+			from, to := old[nsType], after[pid][nsType]
+			if from == "" || to == "" || from == to {
+				continue
+			}
+			changes = append(changes, Change{
+				Kind:   ChangeProcess,
+				Action: "moved",
+				Object: strings.TrimSpace("pid " + pid + " " + afterProc[pid].Name),
+				Detail: fmt.Sprintf("%s %s -> %s", nsType, from, to),
+			})
+		}
+	}
+	return changes
+}
+
+func diffInterfaces(a, b *Snapshot) []Change {
+	var changes []Change
+	for _, ns := range sortedKeys(b.Interfaces) {
+		olds, ok := a.Interfaces[ns]
+		if !ok {
+			// the whole namespace is created
+			continue
+		}
+		before := map[string]Interface{}
+		for _, i := range olds {
+			before[i.Name] = i
+		}
+		after := map[string]Interface{}
+		for _, i := range b.Interfaces[ns] {
+			after[i.Name] = i
+			old, ok := before[i.Name]
+			object := ns + "/" + i.Name
+			if !ok {
+				changes = append(changes, Change{ChangeInterface, "added", object, i.Type})
+				continue
+			}
+			for _, d := range interfaceChanges(old, i) {
+				changes = append(changes, Change{ChangeInterface, "changed", object, d})
+			}
+			if d := counterDeltas(old, i); d != "" {
+				changes = append(changes, Change{ChangeInterface, "counters", object, d})
+			}
+		}
+		for _, i := range olds {
+			if _, ok := after[i.Name]; !ok {
+				changes = append(changes, Change{ChangeInterface, "removed", ns + "/" + i.Name, i.Type})
+			}
+		}
+	}
+	return changes
+}
+
+// interfaceChanges describe changes of mtu, flags and offloads
+func interfaceChanges(a, b Interface) []string {
+	var changes []string
+	if a.MTU != b.MTU {
+		changes = append(changes, fmt.Sprintf("mtu %d -> %d", a.MTU, b.MTU))
+	}
+	if from, to := strings.Join(a.Flags, "|"), strings.Join(b.Flags, "|"); from != to {
+		changes = append(changes, fmt.Sprintf("flags %s -> %s", from, to))
+	}
+	offloads := []struct {
+		name     string
+		from, to bool
+	}{
+		{"gso", a.GSO, b.GSO},
+		{"tso", a.TSO, b.TSO},
+		{"lro", a.LRO, b.LRO},
+		{"gro", a.GRO, b.GRO},
+		{"sg", a.SG, b.SG},
+		{"rx-checksum", a.RxChecksum, b.RxChecksum},
+		{"tx-checksum", a.TxChecksum, b.TxChecksum},
+	}
+	for _, o := range offloads {
+		switch {
+		case !o.from && o.to:
+			changes = append(changes, o.name+" enabled")
+		case o.from && !o.to:
+			changes = append(changes, o.name+" disabled")
+		}
+	}
+	return changes
+}
+
+// counterDeltas describe counters increased from a to b, empty if none
+func counterDeltas(a, b Interface) string {
+	counters := []struct {
+		name     string
+		from, to uint64
+	}{
+		{"rxBytes", a.RxBytes, b.RxBytes},
+		{"txBytes", a.TxBytes, b.TxBytes},
+		{"rxPackets", a.RxPackets, b.RxPackets},
+		{"txPackets", a.TxPackets, b.TxPackets},
+		{"rxErrs", a.RxErrs, b.RxErrs},
+		{"rxDrop", a.RxDrop, b.RxDrop},
+		{"txErrs", a.TxErrs, b.TxErrs},
+		{"txDrop", a.TxDrop, b.TxDrop},
+	}
+	var deltas []string
+	for _, c := range counters {
+		switch {
+		case c.to < c.from:
+			// interface recreated or counter wrapped
+			deltas = append(deltas, c.name+" reset")
+		case c.to > c.from:
+			deltas = append(deltas, fmt.Sprintf("%s +%d", c.name, c.to-c.from))
+		}
+	}
+	return strings.Join(deltas, " ")
+}
+
+// sortedKeys return keys of a map with string keys in order
+func sortedKeys(m interface{}) []string {
+	var keys []string
+	switch m := m.(type) {
+	case map[string]int:
+		for k := range m {
+			keys = append(keys, k)
+		}
+	case map[string][]Interface:
+		for k := range m {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
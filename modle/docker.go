@@ -0,0 +1,89 @@
+/*
+ Copyright 2020  l1b0k
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package modle
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/docker/docker/api/types"
+	docker "github.com/docker/docker/client"
+)
+
+// DockerRuntime list docker containers, including pods created by dockershim
+type DockerRuntime struct {
+	client *docker.Client
+}
+
+func NewDockerRuntime(path string) (*DockerRuntime, error) {
+	client, err := docker.NewClientWithOpts(
+		docker.WithHost("unix://"+path),
+		docker.WithVersion("v1.21"),
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &DockerRuntime{client: client}, nil
+}
+
+func (r *DockerRuntime) Name() string {
+	return "docker"
+}
+
+func (r *DockerRuntime) ListContainers(ctx context.Context) ([]Container, error) {
+	containers, err := r.client.ContainerList(ctx, types.ContainerListOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	var result []Container
+	for _, s := range containers {
+		state, err := r.client.ContainerInspect(ctx, s.ID)
+		if err != nil || state.State == nil {
+			continue
+		}
+		c := Container{
+			ID:           s.ID,
+			Pid:          strconv.Itoa(state.State.Pid),
+			Name:         strings.TrimPrefix(state.Name, "/"),
+			Image:        s.Image,
+			PodUID:       s.Labels["io.kubernetes.pod.uid"],
+			PodNamespace: s.Labels["io.kubernetes.pod.namespace"],
+			PodName:      s.Labels["io.kubernetes.pod.name"],
+		}
+		switch {
+		case s.Labels["io.kubernetes.docker.type"] == "podsandbox":
+			c.Type = ContainerTypeSandbox
+		case s.Labels["io.kubernetes.container.name"] != "":
+			c.Type = ContainerTypeContainer
+			c.Name = s.Labels["io.kubernetes.container.name"]
+		case s.Labels["com.docker.compose.service"] != "":
+			c.Type = ContainerTypeCompose
+			c.Name = fmt.Sprintf("%s/%s", s.Labels["com.docker.compose.project"], s.Labels["com.docker.compose.service"])
+		default:
+			c.Type = ContainerTypeDocker
+		}
+		result = append(result, c)
+	}
+	return result, nil
+}
+
+func (r *DockerRuntime) Close() error {
+	return r.client.Close()
+}
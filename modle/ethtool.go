@@ -0,0 +1,310 @@
+/*
+ Copyright 2020  l1b0k
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package modle
+
+import (
+	"errors"
+	"math"
+	"runtime"
+	"sort"
+	"strconv"
+	"time"
+	"unsafe"
+
+	"github.com/safchain/ethtool"
+	"golang.org/x/sys/unix"
+)
+
+// ethtool commands not covered by github.com/safchain/ethtool, see linux/ethtool.h
+const (
+	ethtoolGRingParam = 0x00000010
+	ethtoolGFeatures  = 0x0000003a
+)
+
+// InterfaceInfo is what ethtool tell about an interface
+type InterfaceInfo struct {
+	NS       string `json:"ns"`
+	Name     string `json:"name"`
+	Driver   string `json:"driver"`
+	Version  string `json:"version"`
+	Firmware string `json:"firmware"`
+	BusInfo  string `json:"busInfo"`
+	// Speed is in Mb/s, -1 if unknown, e.g. link down or a virtual device
+	Speed   int64  `json:"speed"`
+	Duplex  string `json:"duplex"`
+	Autoneg bool   `json:"autoneg"`
+	// Ring and Coalesce are nil when the driver does not support them
+	Ring     *Ring     `json:"ring"`
+	Coalesce *Coalesce `json:"coalesce"`
+	Features []Feature `json:"features"`
+	Stats    []NICStat `json:"stats"`
+}
+
+// Coalesce is the interrupt coalescing settings
+type Coalesce struct {
+	AdaptiveRx bool `json:"adaptiveRx"`
+	AdaptiveTx bool `json:"adaptiveTx"`
+	// Values is the other settings by the names of ethtool -c
+	Values []KeyValue `json:"values"`
+}
+
+// Ring is the ring parameters, current and max of each ring
+type Ring struct {
+	Rx         uint32 `json:"rx"`
+	RxMini     uint32 `json:"rxMini"`
+	RxJumbo    uint32 `json:"rxJumbo"`
+	Tx         uint32 `json:"tx"`
+	RxMax      uint32 `json:"rxMax"`
+	RxMiniMax  uint32 `json:"rxMiniMax"`
+	RxJumboMax uint32 `json:"rxJumboMax"`
+	TxMax      uint32 `json:"txMax"`
+}
+
+// Feature is an offload feature, Fixed ones can not be changed
+type Feature struct {
+	Name      string `json:"name"`
+	Active    bool   `json:"active"`
+	Requested bool   `json:"requested"`
+	Fixed     bool   `json:"fixed"`
+}
+
+// NICStat is a counter of the driver, Rate is its per-second delta, nil before the second sample
+type NICStat struct {
+	Name  string   `json:"name"`
+	Value uint64   `json:"value"`
+	Rate  *float64 `json:"rate"`
+}
+
+// ethtoolSampleTTL is how long the counters of an interface no longer shown are kept
+const ethtoolSampleTTL = 5 * time.Minute
+
+// ethtoolSample is the driver counters of an interface at a time
+type ethtoolSample struct {
+	Time  time.Time
+	Stats map[string]uint64
+}
+
+// ethtoolRingParam is struct ethtool_ringparam
+type ethtoolRingParam struct {
+	cmd               uint32
+	rxMaxPending      uint32
+	rxMiniMaxPending  uint32
+	rxJumboMaxPending uint32
+	txMaxPending      uint32
+	rxPending         uint32
+	rxMiniPending     uint32
+	rxJumboPending    uint32
+	txPending         uint32
+}
+
+// ethtoolFeaturesBlock is struct ethtool_get_features_block
+type ethtoolFeaturesBlock struct {
+	available    uint32
+	requested    uint32
+	active       uint32
+	neverChanged uint32
+}
+
+// ethtoolGetFeatures is struct ethtool_gfeatures
+type ethtoolGetFeatures struct {
+	cmd    uint32
+	size   uint32
+	blocks [ethtool.MAX_FEATURE_BLOCKS]ethtoolFeaturesBlock
+}
+
+// ifreq is struct ifreq carrying a pointer to an ethtool command
+type ifreq struct {
+	name [unix.IFNAMSIZ]byte
+	data uintptr
+}
+
+// GetInterfaceInfo read driver info, link settings, ring, coalesce, features and counters of
+// the interface name in net namespace ns. It is not available in replay as snapshots do not keep it.
+func (d *Dao) GetInterfaceInfo(ns, name string) (*InterfaceInfo, error) {
+	if d.snapshot != nil {
+		return nil, errors.New("interface detail is not kept in snapshots")
+	}
+	info := &InterfaceInfo{NS: ns, Name: name, Speed: -1}
+	var stats map[string]uint64
+	err := d.doInNetNS(ns, func() error {
+		tool, err := ethtool.NewEthtool()
+		if err != nil {
+			return err
+		}
+		defer tool.Close()
+
+		if drv, err := tool.DriverInfo(name); err == nil {
+			info.Driver = drv.Driver
+			info.Version = drv.Version
+			info.Firmware = drv.FwVersion
+			info.BusInfo = drv.BusInfo
+		}
+		cmd := ethtool.EthtoolCmd{}
+		if speed, err := tool.CmdGet(&cmd, name); err == nil {
+			if speed != math.MaxUint32 && speed != 0 {
+				info.Speed = int64(speed)
+			}
+			info.Duplex = duplexNames[cmd.Duplex]
+			info.Autoneg = cmd.Autoneg != 0
+		}
+		if c, err := tool.GetCoalesce(name); err == nil {
+			info.Coalesce = &Coalesce{
+				AdaptiveRx: c.UseAdaptiveRxCoalesce != 0,
+				AdaptiveTx: c.UseAdaptiveTxCoalesce != 0,
+				Values:     coalesceValues(c),
+			}
+		}
+		stats, _ = tool.Stats(name)
+
+		fd, err := unix.Socket(unix.AF_INET, unix.SOCK_DGRAM, unix.IPPROTO_IP)
+		if err != nil {
+			return err
+		}
+		defer unix.Close(fd)
+		ring := ethtoolRingParam{cmd: ethtoolGRingParam}
+		if ethtoolIoctl(fd, name, unsafe.Pointer(&ring)) == nil {
+			info.Ring = &Ring{
+				Rx:         ring.rxPending,
+				RxMini:     ring.rxMiniPending,
+				RxJumbo:    ring.rxJumboPending,
+				Tx:         ring.txPending,
+				RxMax:      ring.rxMaxPending,
+				RxMiniMax:  ring.rxMiniMaxPending,
+				RxJumboMax: ring.rxJumboMaxPending,
+				TxMax:      ring.txMaxPending,
+			}
+		}
+		if names, err := tool.FeatureNames(name); err == nil {
+			info.Features = readFeatures(fd, name, names)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	rates := d.ethtoolRates(ns, name, stats, time.Now())
+	for k, v := range stats {
+		stat := NICStat{Name: k, Value: v}
+		if r, ok := rates[k]; ok {
+			stat.Rate = &r
+		}
+		info.Stats = append(info.Stats, stat)
+	}
+	sort.Slice(info.Stats, func(i, j int) bool { return info.Stats[i].Name < info.Stats[j].Name })
+	return info, nil
+}
+
+// duplexNames is DUPLEX_* of linux/ethtool.h
+var duplexNames = map[uint8]string{
+	0:    "half",
+	1:    "full",
+	0xff: "unknown",
+}
+
+// coalesceValues list coalesce settings other than adaptive switches by the names of ethtool -c
+func coalesceValues(c ethtool.Coalesce) []KeyValue {
+	u := func(v uint32) string { return strconv.FormatUint(uint64(v), 10) }
+	return []KeyValue{
+		{Key: "stats-block-usecs", Value: u(c.StatsBlockCoalesceUsecs)},
+		{Key: "sample-interval", Value: u(c.RateSampleInterval)},
+		{Key: "pkt-rate-low", Value: u(c.PktRateLow)},
+		{Key: "pkt-rate-high", Value: u(c.PktRateHigh)},
+		{Key: "rx-usecs", Value: u(c.RxCoalesceUsecs)},
+		{Key: "rx-frames", Value: u(c.RxMaxCoalescedFrames)},
+		{Key: "rx-usecs-irq", Value: u(c.RxCoalesceUsecsIrq)},
+		{Key: "rx-frames-irq", Value: u(c.RxMaxCoalescedFramesIrq)},
+		{Key: "tx-usecs", Value: u(c.TxCoalesceUsecs)},
+		{Key: "tx-frames", Value: u(c.TxMaxCoalescedFrames)},
+		{Key: "tx-usecs-irq", Value: u(c.TxCoalesceUsecsIrq)},
+		{Key: "tx-frames-irq", Value: u(c.TxMaxCoalescedFramesIrq)},
+		{Key: "rx-usecs-low", Value: u(c.RxCoalesceUsecsLow)},
+		{Key: "rx-frames-low", Value: u(c.RxMaxCoalescedFramesLow)},
+		{Key: "tx-usecs-low", Value: u(c.TxCoalesceUsecsLow)},
+		{Key: "tx-frames-low", Value: u(c.TxMaxCoalescedFramesLow)},
+		{Key: "rx-usecs-high", Value: u(c.RxCoalesceUsecsHigh)},
+		{Key: "rx-frames-high", Value: u(c.RxMaxCoalescedFramesHigh)},
+		{Key: "tx-usecs-high", Value: u(c.TxCoalesceUsecsHigh)},
+		{Key: "tx-frames-high", Value: u(c.TxMaxCoalescedFramesHigh)},
+	}
+}
+
+// readFeatures read active, requested and changeable state of features, sorted by name
+func readFeatures(fd int, name string, names map[string]uint) []Feature {
+	if len(names) == 0 {
+		return nil
+	}
+	gf := ethtoolGetFeatures{cmd: ethtoolGFeatures, size: uint32((len(names) + 31) / 32)}
+	if ethtoolIoctl(fd, name, unsafe.Pointer(&gf)) != nil {
+		return nil
+	}
+	bit := func(v uint32, i uint) bool { return v&(1<<(i%32)) != 0 }
+	features := make([]Feature, 0, len(names))
+	for n, i := range names {
+		b := gf.blocks[i/32]
+		features = append(features, Feature{
+			Name:      n,
+			Active:    bit(b.active, i),
+			Requested: bit(b.requested, i),
+			Fixed:     !bit(b.available, i),
+		})
+	}
+	sort.Slice(features, func(i, j int) bool { return features[i].Name < features[j].Name })
+	return features
+}
+
+// ethtoolIoctl issue SIOCETHTOOL on interface name with data pointing to an ethtool command
+func ethtoolIoctl(fd int, name string, data unsafe.Pointer) error {
+	ifr := ifreq{data: uintptr(data)}
+	copy(ifr.name[:unix.IFNAMSIZ-1], name)
+	_, _, errno := unix.Syscall(unix.SYS_IOCTL, uintptr(fd), ethtool.SIOCETHTOOL, uintptr(unsafe.Pointer(&ifr)))
+	runtime.KeepAlive(data)
+	if errno != 0 {
+		return errno
+	}
+	return nil
+}
+
+// ethtoolRates store stats as the latest sample of the interface in ns, and return per-second delta
+// of each counter since the previous sample. Counters went backwards are left out.
+func (d *Dao) ethtoolRates(ns, name string, stats map[string]uint64, now time.Time) map[string]float64 {
+	d.sampleLock.Lock()
+	defer d.sampleLock.Unlock()
+
+	if d.ethtoolSamples == nil {
+		d.ethtoolSamples = map[string]ethtoolSample{}
+	}
+	key := ns + "/" + name
+	prev, found := d.ethtoolSamples[key]
+	d.ethtoolSamples[key] = ethtoolSample{Time: now, Stats: stats}
+	for k, s := range d.ethtoolSamples {
+		if now.Sub(s.Time) > ethtoolSampleTTL {
+			delete(d.ethtoolSamples, k)
+		}
+	}
+	rates := map[string]float64{}
+	seconds := now.Sub(prev.Time).Seconds()
+	if !found || seconds <= 0 {
+		return rates
+	}
+	for k, v := range stats {
+		if old, ok := prev.Stats[k]; ok && v >= old {
+			rates[k] = float64(v-old) / seconds
+		}
+	}
+	return rates
+}
@@ -0,0 +1,246 @@
+/*
+ Copyright 2020  l1b0k
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package modle
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// NSSample is the process count of a namespace at a refresh
+type NSSample struct {
+	ID        uint   `gorm:"primaryKey;column:id"`
+	Time      int64  `gorm:"column:time;index:idx_ns_sample_time"` // unix seconds
+	Namespace string `gorm:"column:namespace;index:idx_ns_sample_namespace"`
+	NSType    string `gorm:"column:ns_type"`
+	NProcs    int    `gorm:"column:nprocs"`
+}
+
+// TableName overrides the table name
+func (NSSample) TableName() string {
+	return "ns_sample"
+}
+
+// InterfaceSample is the counters of an interface at a refresh
+type InterfaceSample struct {
+	ID        uint   `gorm:"primaryKey;column:id"`
+	Time      int64  `gorm:"column:time;index:idx_interface_sample_time"` // unix seconds
+	Namespace string `gorm:"column:namespace;index:idx_interface_sample_namespace"`
+	Name      string `gorm:"column:name"`
+	RxBytes   uint64 `gorm:"column:rx_bytes"`
+	TxBytes   uint64 `gorm:"column:tx_bytes"`
+	RxPackets uint64 `gorm:"column:rx_packets"`
+	TxPackets uint64 `gorm:"column:tx_packets"`
+	RxErrs    uint64 `gorm:"column:rx_errs"`
+	RxDrop    uint64 `gorm:"column:rx_drop"`
+	TxErrs    uint64 `gorm:"column:tx_errs"`
+	TxDrop    uint64 `gorm:"column:tx_drop"`
+}
+
+// TableName overrides the table name
+func (InterfaceSample) TableName() string {
+	return "interface_sample"
+}
+
+// History configure recording of samples, they are only recorded into an on-disk database
+type History struct {
+	// Retention is how long samples are kept
+	Retention time.Duration
+	// samples older than CompactAfter are thinned out to one per CompactInterval
+	CompactAfter    time.Duration
+	CompactInterval time.Duration
+}
+
+// initHistoryDB open the database file of samples at path
+func initHistoryDB(path string) *gorm.DB {
+	db, err := gorm.Open(sqlite.Open(fmt.Sprintf("file:%s?_journal_mode=WAL", path)), &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		panic("failed to connect database")
+	}
+	err = db.AutoMigrate(&NSSample{}, &InterfaceSample{})
+	if err != nil {
+		panic("failed to migrate database")
+	}
+	return db
+}
+
+// Series is a metric over time, Values hold one value per bucket, NaN when the bucket has no sample
+type Series struct {
+	Name   string
+	Values []float64
+}
+
+// errHistoryDisabled is returned by GetHistory when no database is given to record samples
+var errHistoryDisabled = errors.New("history is not recorded, start with -db")
+
+// Recording return true if samples are recorded, so GetHistory has data
+func (d *Dao) Recording() bool {
+	return d.history != nil
+}
+
+// Record add a sample of process count of every namespace, and counters of every interface
+func (d *Dao) Record(now time.Time) {
+	if d.history == nil {
+		return
+	}
+	var nsSamples []NSSample
+	err := d.DB.Raw("select namespace, ns_type, count(*) as nprocs from proc group by namespace, ns_type").Scan(&nsSamples).Error
+	if err != nil {
+		return
+	}
+	var ifaceSamples []InterfaceSample
+	for i := range nsSamples {
+		nsSamples[i].Time = now.Unix()
+		if nsSamples[i].NSType != "net" {
+			continue
+		}
+		ns := nsSamples[i].Namespace
+		// only counters are recorded, rates are computed when read
+		ifaces, err := d.netNSDetail(ns, nil)
+		if err != nil {
+			// the namespace is gone since the last scan
+			continue
+		}
+		for _, iface := range ifaces {
+			ifaceSamples = append(ifaceSamples, InterfaceSample{
+				Time:      now.Unix(),
+				Namespace: ns,
+				Name:      iface.Name,
+				RxBytes:   iface.RxBytes,
+				TxBytes:   iface.TxBytes,
+				RxPackets: iface.RxPackets,
+				TxPackets: iface.TxPackets,
+				RxErrs:    iface.RxErrs,
+				RxDrop:    iface.RxDrop,
+				TxErrs:    iface.TxErrs,
+				TxDrop:    iface.TxDrop,
+			})
+		}
+	}
+	if len(nsSamples) > 0 {
+		d.historyDB.CreateInBatches(nsSamples, 100)
+	}
+	if len(ifaceSamples) > 0 {
+		d.historyDB.CreateInBatches(ifaceSamples, 100)
+	}
+	d.compact(now)
+}
+
+// compact drop samples out of retention, and keep the first sample of each CompactInterval for old ones
+func (d *Dao) compact(now time.Time) {
+	h := d.history
+	if h.Retention > 0 {
+		expired := now.Add(-h.Retention).Unix()
+		d.historyDB.Where("time < ?", expired).Delete(&NSSample{})
+		d.historyDB.Where("time < ?", expired).Delete(&InterfaceSample{})
+	}
+	interval := int64(h.CompactInterval / time.Second)
+	if h.CompactAfter <= 0 || interval <= 0 {
+		return
+	}
+	old := now.Add(-h.CompactAfter).Unix()
+	d.historyDB.Exec("delete from ns_sample where time < ? and id not in"+
+		" (select min(id) from ns_sample where time < ? group by namespace, ns_type, time / ?)", old, old, interval)
+	d.historyDB.Exec("delete from interface_sample where time < ? and id not in"+
+		" (select min(id) from interface_sample where time < ? group by namespace, name, time / ?)", old, old, interval)
+}
+
+// GetHistory return how ns evolved from since to now, split into n buckets.
+// It has the process count, the max of each bucket, and for a net namespace rx and tx drops of each interface
+// in each bucket. It is an error when samples are not recorded.
+func (d *Dao) GetHistory(ns string, since, now time.Time, n int) ([]Series, error) {
+	if d.history == nil {
+		return nil, errHistoryDisabled
+	}
+	if n <= 0 || !now.After(since) {
+		return nil, nil
+	}
+	width := float64(now.Unix()-since.Unix()) / float64(n)
+	bucket := func(t int64) int {
+		b := int(float64(t-since.Unix()) / width)
+		if b >= n {
+			b = n - 1
+		}
+		return b
+	}
+	empty := func() []float64 {
+		v := make([]float64, n)
+		for i := range v {
+			v[i] = math.NaN()
+		}
+		return v
+	}
+
+	var nsSamples []NSSample
+	d.historyDB.Where("namespace = ? and time >= ?", ns, since.Unix()).Order("time").Find(&nsSamples)
+	nprocs := Series{Name: "nprocs", Values: empty()}
+	for _, s := range nsSamples {
+		b := bucket(s.Time)
+		if math.IsNaN(nprocs.Values[b]) || float64(s.NProcs) > nprocs.Values[b] {
+			nprocs.Values[b] = float64(s.NProcs)
+		}
+	}
+	series := []Series{nprocs}
+
+	// the sample before since is the base of the first delta
+	var ifaceSamples []InterfaceSample
+	d.historyDB.Where("namespace = ? and time >= ?", ns, since.Unix()-int64(d.history.CompactInterval/time.Second)).
+		Order("time").Find(&ifaceSamples)
+	byName := map[string][]InterfaceSample{}
+	var names []string
+	for _, s := range ifaceSamples {
+		if _, ok := byName[s.Name]; !ok {
+			names = append(names, s.Name)
+		}
+		byName[s.Name] = append(byName[s.Name], s)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		rx := Series{Name: name + " rxDrop", Values: empty()}
+		tx := Series{Name: name + " txDrop", Values: empty()}
+		samples := byName[name]
+		for i := 1; i < len(samples); i++ {
+			prev, cur := samples[i-1], samples[i]
+			if cur.Time < since.Unix() {
+				continue
+			}
+			b := bucket(cur.Time)
+			rx.Values[b] = addDelta(rx.Values[b], prev.RxDrop, cur.RxDrop)
+			tx.Values[b] = addDelta(tx.Values[b], prev.TxDrop, cur.TxDrop)
+		}
+		series = append(series, rx, tx)
+	}
+	return series, nil
+}
+
+// addDelta add the increase of a counter to v, a counter reset count as no increase
+func addDelta(v float64, prev, cur uint64) float64 {
+	if math.IsNaN(v) {
+		v = 0
+	}
+	if cur > prev {
+		v += float64(cur - prev)
+	}
+	return v
+}
@@ -0,0 +1,117 @@
+/*
+ Copyright 2020  l1b0k
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package modle
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// sysvIPC describe /proc/sysvipc files and which columns hold the id, size and count
+var sysvIPC = []struct {
+	Type  string
+	ID    string
+	Size  string
+	Count string
+}{
+	{Type: "shm", ID: "shmid", Size: "size", Count: "nattch"},
+	{Type: "msg", ID: "msqid", Size: "cbytes", Count: "qnum"},
+	{Type: "sem", ID: "semid", Size: "nsems"},
+}
+
+// IPCObject is a SysV shared memory segment, message queue or semaphore set
+type IPCObject struct {
+	Type  string `json:"type"`
+	Key   string `json:"key"`
+	ID    int    `json:"id"`
+	Perms string `json:"perms"`
+	UID   int    `json:"uid"`
+	GID   int    `json:"gid"`
+	// Size is bytes of shm and msg, semaphores of sem
+	Size uint64 `json:"size"`
+	// Count is nattch of shm and qnum of msg, nil for sem
+	Count *uint64 `json:"count"`
+}
+
+// GetIPCDetail return SysV IPC objects of the ipc namespace
+func (d *Dao) GetIPCDetail(ns string) []IPCObject {
+	var data []IPCObject
+	if d.replayTable(ns, tableIPC, &data) {
+		return data
+	}
+	pids := d.GetPIDs(ns)
+	if len(pids) == 0 {
+		return data
+	}
+
+	_ = d.doInNS(pids[0], "ipc", func() error {
+		for _, t := range sysvIPC {
+			objs, err := readSysvIPC(fmt.Sprintf("/proc/sysvipc/%s", t.Type))
+			if err != nil {
+				continue
+			}
+			for _, obj := range objs {
+				key, _ := strconv.ParseInt(obj["key"], 10, 32)
+				o := IPCObject{
+					Type:  t.Type,
+					Key:   fmt.Sprintf("0x%08x", uint32(key)),
+					Perms: obj["perms"],
+				}
+				o.ID, _ = strconv.Atoi(obj[t.ID])
+				o.UID, _ = strconv.Atoi(obj["uid"])
+				o.GID, _ = strconv.Atoi(obj["gid"])
+				o.Size, _ = strconv.ParseUint(obj[t.Size], 10, 64)
+				if t.Count != "" {
+					count, _ := strconv.ParseUint(obj[t.Count], 10, 64)
+					o.Count = &count
+				}
+				data = append(data, o)
+			}
+		}
+		return nil
+	})
+	return data
+}
+
+// readSysvIPC parse a /proc/sysvipc file into maps keyed by its header
+func readSysvIPC(path string) ([]map[string]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var header []string
+	var objs []map[string]string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if header == nil {
+			header = fields
+			continue
+		}
+		obj := make(map[string]string, len(header))
+		for i := 0; i < len(header) && i < len(fields); i++ {
+			obj[header[i]] = fields[i]
+		}
+		objs = append(objs, obj)
+	}
+	return objs, scanner.Err()
+}
@@ -0,0 +1,245 @@
+/*
+ Copyright 2020  l1b0k
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package modle
+
+import (
+	"encoding/binary"
+	"fmt"
+	"net"
+	"strconv"
+
+	"github.com/vishvananda/netlink"
+	"github.com/vishvananda/netlink/nl"
+	"golang.org/x/sys/unix"
+)
+
+// bridgePortStates is BR_STATE_* of linux/if_bridge.h
+var bridgePortStates = map[uint8]string{
+	0: "disabled",
+	1: "listening",
+	2: "learning",
+	3: "forwarding",
+	4: "blocking",
+}
+
+var macvlanModes = map[netlink.MacvlanMode]string{
+	netlink.MACVLAN_MODE_PRIVATE:  "private",
+	netlink.MACVLAN_MODE_VEPA:     "vepa",
+	netlink.MACVLAN_MODE_BRIDGE:   "bridge",
+	netlink.MACVLAN_MODE_PASSTHRU: "passthru",
+	netlink.MACVLAN_MODE_SOURCE:   "source",
+}
+
+var ipvlanModes = map[netlink.IPVlanMode]string{
+	netlink.IPVLAN_MODE_L2:  "l2",
+	netlink.IPVLAN_MODE_L3:  "l3",
+	netlink.IPVLAN_MODE_L3S: "l3s",
+}
+
+var ipvlanFlags = map[netlink.IPVlanFlag]string{
+	netlink.IPVLAN_FLAG_BRIDGE:  "bridge",
+	netlink.IPVLAN_FLAG_PRIVATE: "private",
+	netlink.IPVLAN_FLAG_VEPA:    "vepa",
+}
+
+// linkDetails decode type specific attributes of l, such as "vlan 100/802.1Q" or "master br0 forwarding".
+// names map ifindex to names in the namespace of l, it is called in that namespace.
+func linkDetails(l netlink.Link, names map[int]string) []string {
+	var details []string
+	attrs := l.Attrs()
+	if attrs.MasterIndex > 0 {
+		master := "master " + names[attrs.MasterIndex]
+		if slave, ok := attrs.Slave.(*netlink.BondSlave); ok {
+			master += fmt.Sprintf(" %s mii %s", slave.State, slave.MiiStatus)
+		} else if kind, data, err := linkSlaveData(attrs.Index); err == nil && kind == "bridge" {
+			if state, ok := data[nl.IFLA_BRPORT_STATE]; ok && len(state) > 0 {
+				master += " " + bridgePortStates[state[0]]
+			}
+		}
+		details = append(details, master)
+	}
+
+	switch l := l.(type) {
+	case *netlink.Bridge:
+		stp := "stp off"
+		if on, err := bridgeSTP(attrs.Index); err == nil && on {
+			stp = "stp on"
+		}
+		details = append(details, stp)
+		if l.VlanFiltering != nil && *l.VlanFiltering {
+			details = append(details, "vlan_filtering")
+		}
+	case *netlink.Bond:
+		details = append(details, "mode "+l.Mode.String())
+		if l.ActiveSlave > 0 {
+			details = append(details, "active "+names[l.ActiveSlave])
+		}
+	case *netlink.Vlan:
+		details = append(details, fmt.Sprintf("vlan %d/%s", l.VlanId, l.VlanProtocol))
+	case *netlink.Vxlan:
+		vxlan := "vni " + strconv.Itoa(l.VxlanId)
+		if l.Group != nil {
+			vxlan += " remote " + l.Group.String()
+		}
+		vxlan += " port " + strconv.Itoa(l.Port)
+		if l.VtepDevIndex > 0 {
+			vxlan += " dev " + names[l.VtepDevIndex]
+		}
+		details = append(details, vxlan)
+	case *netlink.IPVlan:
+		details = append(details, "mode "+ipvlanModes[l.Mode]+" "+ipvlanFlags[l.Flag])
+	case *netlink.Macvtap:
+		details = append(details, "mode "+macvlanModes[l.Mode])
+	case *netlink.Macvlan:
+		details = append(details, "mode "+macvlanModes[l.Mode])
+	case *netlink.Tuntap:
+		mode := "tun"
+		if l.Mode == netlink.TUNTAP_MODE_TAP {
+			mode = "tap"
+		}
+		// netlink leave Owner 0 when the device has no owner, so ask for the attribute itself
+		if owner, ok := tunOwner(attrs.Index); ok {
+			mode += " owner " + userName(uint64(owner))
+		}
+		details = append(details, mode)
+	}
+	return details
+}
+
+// linkInfo read nested attributes of IFLA_LINKINFO of the link index in current net namespace
+func linkInfo(index int) (map[uint16][]byte, error) {
+	req := nl.NewNetlinkRequest(unix.RTM_GETLINK, unix.NLM_F_ACK)
+	msg := nl.NewIfInfomsg(unix.AF_UNSPEC)
+	msg.Index = int32(index)
+	req.AddData(msg)
+	msgs, err := req.Execute(unix.NETLINK_ROUTE, unix.RTM_NEWLINK)
+	if err != nil {
+		return nil, err
+	}
+	if len(msgs) == 0 {
+		return nil, fmt.Errorf("link %d not found", index)
+	}
+	attrs, err := nl.ParseRouteAttr(msgs[0][unix.SizeofIfInfomsg:])
+	if err != nil {
+		return nil, err
+	}
+	for _, attr := range attrs {
+		if attr.Attr.Type == unix.IFLA_LINKINFO {
+			return nestedAttrs(attr.Value)
+		}
+	}
+	return nil, fmt.Errorf("link %d has no linkinfo", index)
+}
+
+// nestedAttrs parse attributes nested in b by type
+func nestedAttrs(b []byte) (map[uint16][]byte, error) {
+	attrs, err := nl.ParseRouteAttr(b)
+	if err != nil {
+		return nil, err
+	}
+	m := make(map[uint16][]byte, len(attrs))
+	for _, attr := range attrs {
+		m[attr.Attr.Type&^unix.NLA_F_NESTED] = attr.Value
+	}
+	return m, nil
+}
+
+// linkSlaveData return the kind of master of the link index and its IFLA_INFO_SLAVE_DATA
+func linkSlaveData(index int) (string, map[uint16][]byte, error) {
+	info, err := linkInfo(index)
+	if err != nil {
+		return "", nil, err
+	}
+	kind := string(trimNull(info[unix.IFLA_INFO_SLAVE_KIND]))
+	data, err := nestedAttrs(info[unix.IFLA_INFO_SLAVE_DATA])
+	return kind, data, err
+}
+
+// bridgeSTP tell whether STP is enabled on the bridge index
+func bridgeSTP(index int) (bool, error) {
+	info, err := linkInfo(index)
+	if err != nil {
+		return false, err
+	}
+	data, err := nestedAttrs(info[unix.IFLA_INFO_DATA])
+	if err != nil {
+		return false, err
+	}
+	state := data[unix.IFLA_BR_STP_STATE]
+	if len(state) < 4 {
+		return false, fmt.Errorf("bridge %d has no stp state", index)
+	}
+	return nl.NativeEndian().Uint32(state) != 0, nil
+}
+
+// tunOwner return the owner uid of the tun/tap device index, false if it has none
+func tunOwner(index int) (uint32, bool) {
+	info, err := linkInfo(index)
+	if err != nil {
+		return 0, false
+	}
+	data, err := nestedAttrs(info[unix.IFLA_INFO_DATA])
+	if err != nil {
+		return 0, false
+	}
+	owner := data[unix.IFLA_TUN_OWNER]
+	if len(owner) < 4 {
+		return 0, false
+	}
+	return nl.NativeEndian().Uint32(owner), true
+}
+
+// geneveTunnel return the vni and remote of the geneve device index, such as "vni 1 10.0.0.2:6081".
+// netlink has no type for geneve, so IFLA_INFO_DATA is decoded here.
+func geneveTunnel(index int) (string, error) {
+	info, err := linkInfo(index)
+	if err != nil {
+		return "", err
+	}
+	data, err := nestedAttrs(info[unix.IFLA_INFO_DATA])
+	if err != nil {
+		return "", err
+	}
+	id := data[unix.IFLA_GENEVE_ID]
+	if len(id) < 4 {
+		return "", fmt.Errorf("geneve %d has no vni", index)
+	}
+	tunnel := "vni " + strconv.FormatUint(uint64(nl.NativeEndian().Uint32(id)), 10)
+	var remote net.IP
+	if r := data[unix.IFLA_GENEVE_REMOTE]; len(r) == net.IPv4len {
+		remote = net.IP(r)
+	} else if r := data[unix.IFLA_GENEVE_REMOTE6]; len(r) == net.IPv6len {
+		remote = net.IP(r)
+	}
+	if remote != nil {
+		// the port is in network byte order, 6081 unless set
+		port := 6081
+		if p := data[unix.IFLA_GENEVE_PORT]; len(p) >= 2 {
+			port = int(binary.BigEndian.Uint16(p))
+		}
+		tunnel += " " + net.JoinHostPort(remote.String(), strconv.Itoa(port))
+	}
+	return tunnel, nil
+}
+
+// trimNull drop the trailing NUL of a netlink string
+func trimNull(b []byte) []byte {
+	for len(b) > 0 && b[len(b)-1] == 0 {
+		b = b[:len(b)-1]
+	}
+	return b
+}
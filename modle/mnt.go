@@ -0,0 +1,179 @@
+/*
+ Copyright 2020  l1b0k
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package modle
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// MountInfo is a line of /proc/<pid>/mountinfo, see proc(5)
+type MountInfo struct {
+	ID           int
+	Parent       int
+	MajorMinor   string
+	Root         string
+	MountPoint   string
+	Options      string
+	Optional     []string
+	FSType       string
+	Source       string
+	SuperOptions string
+}
+
+// Propagation return propagation type of the mount, shared/master/slave or private when none
+func (m *MountInfo) Propagation() string {
+	var s []string
+	for _, o := range m.Optional {
+		switch {
+		case strings.HasPrefix(o, "master:"):
+			s = append(s, "slave("+o+")")
+		case strings.HasPrefix(o, "shared:"), strings.HasPrefix(o, "propagate_from:"), o == "unbindable":
+			s = append(s, o)
+		}
+	}
+	if len(s) == 0 {
+		return "private"
+	}
+	return strings.Join(s, ",")
+}
+
+// Mount is a mount as findmnt show it
+type Mount struct {
+	ID     int `json:"id"`
+	Parent int `json:"parent"`
+	// Source has the subtree in brackets for a bind mount, e.g. /dev/sda1[/data]
+	Source      string `json:"source"`
+	Target      string `json:"target"`
+	FSType      string `json:"fstype"`
+	Options     string `json:"options"`
+	Propagation string `json:"propagation"`
+}
+
+// GetMountDetail return mounts of the mnt namespace, read from mountinfo of the first process in it
+func (d *Dao) GetMountDetail(ns string) []Mount {
+	var data []Mount
+	if d.replayTable(ns, tableMounts, &data) {
+		return data
+	}
+	pids := d.GetPIDs(ns)
+	if len(pids) == 0 {
+		return data
+	}
+
+	mounts, err := readMountInfo(d.procPath(pids[0], "mountinfo"))
+	if err != nil {
+		return data
+	}
+	for _, m := range mounts {
+		source := m.Source
+		if m.Root != "/" {
+			// bind mount, show the subtree like findmnt
+			source = fmt.Sprintf("%s[%s]", m.Source, m.Root)
+		}
+		data = append(data, Mount{
+			ID:          m.ID,
+			Parent:      m.Parent,
+			Source:      source,
+			Target:      m.MountPoint,
+			FSType:      m.FSType,
+			Options:     m.Options,
+			Propagation: m.Propagation(),
+		})
+	}
+	return data
+}
+
+// readMountInfo parse /proc/<pid>/mountinfo
+func readMountInfo(path string) ([]MountInfo, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var mounts []MountInfo
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		m, err := parseMountInfo(scanner.Text())
+		if err != nil {
+			return nil, err
+		}
+		mounts = append(mounts, m)
+	}
+	return mounts, scanner.Err()
+}
+
+// parseMountInfo parse a single mountinfo line
+// 36 35 98:0 /mnt1 /mnt2 rw,noatime master:1 - ext3 /dev/root rw,errors=continue
+func parseMountInfo(line string) (MountInfo, error) {
+	var m MountInfo
+	fields := strings.Fields(line)
+	sep := -1
+	for i := 6; i < len(fields); i++ {
+		if fields[i] == "-" {
+			sep = i
+			break
+		}
+	}
+	if sep < 0 || len(fields) < sep+3 {
+		return m, fmt.Errorf("bad mountinfo line %q", line)
+	}
+
+	var err error
+	m.ID, err = strconv.Atoi(fields[0])
+	if err != nil {
+		return m, err
+	}
+	m.Parent, err = strconv.Atoi(fields[1])
+	if err != nil {
+		return m, err
+	}
+	m.MajorMinor = fields[2]
+	m.Root = unescapeMountField(fields[3])
+	m.MountPoint = unescapeMountField(fields[4])
+	m.Options = fields[5]
+	m.Optional = fields[6:sep]
+	m.FSType = fields[sep+1]
+	m.Source = unescapeMountField(fields[sep+2])
+	if len(fields) > sep+3 {
+		m.SuperOptions = fields[sep+3]
+	}
+	return m, nil
+}
+
+// unescapeMountField decode octal escapes such as \040 used by the kernel for space, tab, newline and backslash
+func unescapeMountField(s string) string {
+	if !strings.Contains(s, `\`) {
+		return s
+	}
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+4 <= len(s) {
+			if v, err := strconv.ParseUint(s[i+1:i+4], 8, 8); err == nil {
+				b.WriteByte(byte(v))
+				i += 3
+				continue
+			}
+		}
+		b.WriteByte(s[i])
+	}
+	return b.String()
+}
@@ -0,0 +1,87 @@
+/*
+ Copyright 2020  l1b0k
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package modle
+
+import (
+	"sort"
+
+	"github.com/c9s/goprocinfo/linux"
+	"github.com/vishvananda/netlink"
+)
+
+// netDevice is an interface known by netlink, /proc/net/dev or both
+type netDevice struct {
+	// Link is nil when only /proc/net/dev know the interface, e.g. it was created between the two reads
+	Link netlink.Link
+	// Stat is from /proc/net/dev, or from netlink statistics when /proc/net/dev miss the interface
+	Stat linux.NetworkStat
+}
+
+// mergeNetDevices join netlink links and /proc/net/dev stats by name, links are deduplicated by ifindex.
+// Interfaces found in only one source are kept, in ifindex order, then those only in /proc/net/dev by name.
+func mergeNetDevices(links []netlink.Link, stats []linux.NetworkStat) []netDevice {
+	byName := make(map[string]linux.NetworkStat, len(stats))
+	for _, s := range stats {
+		byName[s.Iface] = s
+	}
+
+	var devices []netDevice
+	seenIndex := map[int]bool{}
+	seenName := map[string]bool{}
+	for _, l := range links {
+		attrs := l.Attrs()
+		if attrs == nil || seenIndex[attrs.Index] || seenName[attrs.Name] {
+			continue
+		}
+		seenIndex[attrs.Index] = true
+		seenName[attrs.Name] = true
+		stat, ok := byName[attrs.Name]
+		if !ok {
+			stat = linkStat(attrs)
+		}
+		devices = append(devices, netDevice{Link: l, Stat: stat})
+	}
+	sort.SliceStable(devices, func(i, j int) bool {
+		return devices[i].Link.Attrs().Index < devices[j].Link.Attrs().Index
+	})
+
+	var orphans []netDevice
+	for _, s := range stats {
+		if !seenName[s.Iface] {
+			seenName[s.Iface] = true
+			orphans = append(orphans, netDevice{Stat: s})
+		}
+	}
+	sort.Slice(orphans, func(i, j int) bool { return orphans[i].Stat.Iface < orphans[j].Stat.Iface })
+	return append(devices, orphans...)
+}
+
+// linkStat convert netlink statistics of a link to the counters of /proc/net/dev
+func linkStat(attrs *netlink.LinkAttrs) linux.NetworkStat {
+	stat := linux.NetworkStat{Iface: attrs.Name}
+	if s := attrs.Statistics; s != nil {
+		stat.RxBytes = s.RxBytes
+		stat.TxBytes = s.TxBytes
+		stat.RxPackets = s.RxPackets
+		stat.TxPackets = s.TxPackets
+		stat.RxErrs = s.RxErrors
+		stat.RxDrop = s.RxDropped
+		stat.TxErrs = s.TxErrors
+		stat.TxDrop = s.TxDropped
+	}
+	return stat
+}
@@ -0,0 +1,103 @@
+/*
+ Copyright 2020  l1b0k
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package modle
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/c9s/goprocinfo/linux"
+	"github.com/vishvananda/netlink"
+)
+
+func link(index int, name string, stats *netlink.LinkStatistics) netlink.Link {
+	return &netlink.Device{LinkAttrs: netlink.LinkAttrs{Index: index, Name: name, Statistics: stats}}
+}
+
+func TestMergeNetDevices(t *testing.T) {
+	type device struct {
+		Index int // 0 when only /proc/net/dev know the interface
+		Name  string
+		Rx    uint64
+	}
+	tests := []struct {
+		name  string
+		links []netlink.Link
+		stats []linux.NetworkStat
+		want  []device
+	}{
+		{
+			name:  "joined by name in ifindex order",
+			links: []netlink.Link{link(2, "eth0", nil), link(1, "lo", nil)},
+			stats: []linux.NetworkStat{{Iface: "lo", RxBytes: 10}, {Iface: "eth0", RxBytes: 20}},
+			want:  []device{{1, "lo", 10}, {2, "eth0", 20}},
+		},
+		{
+			name:  "only in /proc/net/dev",
+			links: []netlink.Link{link(1, "lo", nil)},
+			stats: []linux.NetworkStat{{Iface: "lo", RxBytes: 10}, {Iface: "veth0", RxBytes: 30}},
+			want:  []device{{1, "lo", 10}, {0, "veth0", 30}},
+		},
+		{
+			name:  "only in netlink use link statistics",
+			links: []netlink.Link{link(1, "lo", nil), link(3, "tap0", &netlink.LinkStatistics{RxBytes: 40})},
+			stats: []linux.NetworkStat{{Iface: "lo", RxBytes: 10}},
+			want:  []device{{1, "lo", 10}, {3, "tap0", 40}},
+		},
+		{
+			name:  "only in netlink without statistics",
+			links: []netlink.Link{link(3, "tap0", nil)},
+			want:  []device{{3, "tap0", 0}},
+		},
+		{
+			name:  "duplicate ifindex keep the first link",
+			links: []netlink.Link{link(2, "eth0", nil), link(2, "eth1", nil)},
+			stats: []linux.NetworkStat{{Iface: "eth0", RxBytes: 20}},
+			want:  []device{{2, "eth0", 20}},
+		},
+		{
+			name:  "name collision after rename keep the first link",
+			links: []netlink.Link{link(2, "eth0", nil), link(5, "eth0", nil)},
+			stats: []linux.NetworkStat{{Iface: "eth0", RxBytes: 20}},
+			want:  []device{{2, "eth0", 20}},
+		},
+		{
+			name:  "orphans sorted by name after links",
+			links: []netlink.Link{link(1, "lo", nil)},
+			stats: []linux.NetworkStat{{Iface: "zz"}, {Iface: "lo"}, {Iface: "aa"}, {Iface: "aa"}},
+			want:  []device{{1, "lo", 0}, {0, "aa", 0}, {0, "zz", 0}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []device
+			for _, d := range mergeNetDevices(tt.links, tt.stats) {
+				dev := device{Name: d.Stat.Iface, Rx: d.Stat.RxBytes}
+				if d.Link != nil {
+					dev.Index = d.Link.Attrs().Index
+					if d.Link.Attrs().Name != d.Stat.Iface {
+						t.Errorf("link %s has stat of %s", d.Link.Attrs().Name, d.Stat.Iface)
+					}
+				}
+				got = append(got, dev)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("mergeNetDevices() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,113 @@
+/*
+ Copyright 2020  l1b0k
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package modle
+
+import (
+	"bufio"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// nodeRoot is where NUMA nodes are listed in sysfs
+const nodeRoot = "/sys/devices/system/node"
+
+var (
+	cpuNodeOnce sync.Once
+	cpuNode     map[int]int
+)
+
+// numaNode return the NUMA node of cpu, -1 if unknown
+func numaNode(cpu int) int {
+	cpuNodeOnce.Do(func() {
+		cpuNode = map[int]int{}
+		dirs, _ := filepath.Glob(filepath.Join(nodeRoot, "node[0-9]*"))
+		for _, dir := range dirs {
+			node, err := strconv.Atoi(strings.TrimPrefix(filepath.Base(dir), "node"))
+			if err != nil {
+				continue
+			}
+			b, err := ioutil.ReadFile(filepath.Join(dir, "cpulist"))
+			if err != nil {
+				continue
+			}
+			cpus, err := ParseCPUList(strings.TrimSpace(string(b)))
+			if err != nil {
+				continue
+			}
+			for _, c := range cpus {
+				cpuNode[c] = node
+			}
+		}
+	})
+	if node, ok := cpuNode[cpu]; ok {
+		return node
+	}
+	return -1
+}
+
+// ParseCPUList parse a cpulist such as "0-3,8" in /proc and /sys into cpu numbers
+func ParseCPUList(s string) ([]int, error) {
+	var cpus []int
+	if s == "" {
+		return cpus, nil
+	}
+	for _, part := range strings.Split(s, ",") {
+		bounds := strings.SplitN(part, "-", 2)
+		lo, err := strconv.Atoi(bounds[0])
+		if err != nil {
+			return nil, fmt.Errorf("invalid cpulist %q", s)
+		}
+		hi := lo
+		if len(bounds) == 2 {
+			if hi, err = strconv.Atoi(bounds[1]); err != nil || hi < lo {
+				return nil, fmt.Errorf("invalid cpulist %q", s)
+			}
+		}
+		for c := lo; c <= hi; c++ {
+			cpus = append(cpus, c)
+		}
+	}
+	return cpus, nil
+}
+
+// readAllowedLists return Cpus_allowed_list and Mems_allowed_list of a /proc/<pid>/status file
+func readAllowedLists(path string) (cpus, mems string, err error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", "", err
+	}
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		parts := strings.SplitN(scanner.Text(), ":", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		switch parts[0] {
+		case "Cpus_allowed_list":
+			cpus = strings.TrimSpace(parts[1])
+		case "Mems_allowed_list":
+			mems = strings.TrimSpace(parts[1])
+		}
+	}
+	return cpus, mems, scanner.Err()
+}
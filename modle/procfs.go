@@ -0,0 +1,56 @@
+/*
+ Copyright 2020  l1b0k
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package modle
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"strconv"
+)
+
+// DefaultProcRoot is where procfs is mounted on the host
+const DefaultProcRoot = "/proc"
+
+// ListPids return pids of all processes under procfs root
+func ListPids(root string) ([]int32, error) {
+	entries, err := ioutil.ReadDir(root)
+	if err != nil {
+		return nil, err
+	}
+	var pids []int32
+	for _, e := range entries {
+		if !e.IsDir() {
+			continue
+		}
+		pid, err := strconv.ParseInt(e.Name(), 10, 32)
+		if err != nil {
+			continue
+		}
+		pids = append(pids, int32(pid))
+	}
+	return pids, nil
+}
+
+// procPath return the path of elem under the procfs directory of pid, e.g. procPath(1, "ns", "net")
+func procPath(root string, pid int, elem ...string) string {
+	return filepath.Join(append([]string{root, strconv.Itoa(pid)}, elem...)...)
+}
+
+// procPath return the path of elem under the procfs directory of pid in d.ProcRoot
+func (d *Dao) procPath(pid int, elem ...string) string {
+	return procPath(d.ProcRoot, pid, elem...)
+}
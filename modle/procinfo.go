@@ -0,0 +1,206 @@
+/*
+ Copyright 2020  l1b0k
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package modle
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ProcInfo is the detail of a process read from /proc/<pid>
+type ProcInfo struct {
+	Pid int `json:"pid"`
+	// Status is the lines of /proc/<pid>/status, in file order
+	Status []KeyValue `json:"status"`
+	Limits []Limit    `json:"limits"`
+	// Environ is the KEY=VALUE entries of /proc/<pid>/environ
+	Environ []string `json:"environ"`
+	// Cwd, Exe and Root are the targets of the links, or the error reading them
+	Cwd  string `json:"cwd"`
+	Exe  string `json:"exe"`
+	Root string `json:"root"`
+	FDs  []FD   `json:"fds"`
+	// Capabilities is the Cap* sets of status decoded to names
+	Capabilities []CapabilitySet `json:"capabilities"`
+}
+
+// KeyValue is a line of a "key: value" file
+type KeyValue struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+}
+
+// Limit is a line of /proc/<pid>/limits
+type Limit struct {
+	Name  string `json:"name"`
+	Soft  string `json:"soft"`
+	Hard  string `json:"hard"`
+	Units string `json:"units"`
+}
+
+// kinds of FD
+const (
+	FDFile   = "file"
+	FDSocket = "socket"
+	FDPipe   = "pipe"
+	FDAnon   = "anon"
+	FDOther  = "other"
+)
+
+// FD is an open file of a process
+type FD struct {
+	FD     int    `json:"fd"`
+	Kind   string `json:"kind"`
+	Target string `json:"target"`
+	// Detail resolve a socket to its protocol and addresses, empty if unknown
+	Detail string `json:"detail"`
+}
+
+// GetProcInfo read the detail of pid, it is not available in replay as snapshots do not keep it
+func (d *Dao) GetProcInfo(pid int) (*ProcInfo, error) {
+	if d.snapshot != nil {
+		return nil, errors.New("process detail is not kept in snapshots")
+	}
+	info := &ProcInfo{Pid: pid}
+	status, err := readKeyValues(d.procPath(pid, "status"))
+	if err != nil {
+		return nil, err
+	}
+	info.Status = status
+	info.Capabilities = decodeCapabilities(status)
+	info.Limits, _ = readLimits(d.procPath(pid, "limits"))
+	if b, err := ioutil.ReadFile(d.procPath(pid, "environ")); err == nil {
+		for _, e := range bytes.Split(b, []byte{0}) {
+			if len(e) > 0 {
+				info.Environ = append(info.Environ, string(e))
+			}
+		}
+	}
+	info.Cwd = readLink(d.procPath(pid, "cwd"))
+	info.Exe = readLink(d.procPath(pid, "exe"))
+	info.Root = readLink(d.procPath(pid, "root"))
+	info.FDs = d.readFDs(pid)
+	return info, nil
+}
+
+// readKeyValues read a file of "key: value" lines, such as /proc/<pid>/status
+func readKeyValues(path string) ([]KeyValue, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var kvs []KeyValue
+	for _, line := range strings.Split(string(b), "\n") {
+		parts := strings.SplitN(line, ":", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		kvs = append(kvs, KeyValue{Key: parts[0], Value: strings.TrimSpace(parts[1])})
+	}
+	return kvs, nil
+}
+
+// readLimits parse /proc/<pid>/limits, columns are located by the header as names contain spaces
+func readLimits(path string) ([]Limit, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	lines := strings.Split(string(b), "\n")
+	soft := strings.Index(lines[0], "Soft Limit")
+	hard := strings.Index(lines[0], "Hard Limit")
+	units := strings.Index(lines[0], "Units")
+	if soft < 0 || hard < soft || units < hard {
+		return nil, fmt.Errorf("unexpected header of %s", path)
+	}
+	column := func(line string, from, to int) string {
+		if from >= len(line) {
+			return ""
+		}
+		if to > len(line) || to < 0 {
+			to = len(line)
+		}
+		return strings.TrimSpace(line[from:to])
+	}
+	var limits []Limit
+	for _, line := range lines[1:] {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		limits = append(limits, Limit{
+			Name:  column(line, 0, soft),
+			Soft:  column(line, soft, hard),
+			Hard:  column(line, hard, units),
+			Units: column(line, units, -1),
+		})
+	}
+	return limits, nil
+}
+
+// readLink return the target of a link, or the error in brackets
+func readLink(path string) string {
+	target, err := os.Readlink(path)
+	if err != nil {
+		if pe, ok := err.(*os.PathError); ok {
+			err = pe.Err
+		}
+		return fmt.Sprintf("(%s)", err)
+	}
+	return target
+}
+
+// readFDs list open files of pid sorted by fd, sockets are resolved in the network namespace of pid
+func (d *Dao) readFDs(pid int) []FD {
+	entries, err := ioutil.ReadDir(d.procPath(pid, "fd"))
+	if err != nil {
+		return nil
+	}
+	var sockets map[string]string
+	var fds []FD
+	for _, e := range entries {
+		n, err := strconv.Atoi(e.Name())
+		if err != nil {
+			continue
+		}
+		fd := FD{FD: n, Target: readLink(d.procPath(pid, "fd", e.Name()))}
+		switch {
+		case strings.HasPrefix(fd.Target, "socket:["):
+			fd.Kind = FDSocket
+			if sockets == nil {
+				sockets = d.readSockets(pid)
+			}
+			fd.Detail = sockets[strings.TrimSuffix(strings.TrimPrefix(fd.Target, "socket:["), "]")]
+		case strings.HasPrefix(fd.Target, "pipe:["):
+			fd.Kind = FDPipe
+		case strings.HasPrefix(fd.Target, "anon_inode:"):
+			fd.Kind = FDAnon
+		case strings.HasPrefix(fd.Target, "/"):
+			fd.Kind = FDFile
+		default:
+			fd.Kind = FDOther
+		}
+		fds = append(fds, fd)
+	}
+	sort.Slice(fds, func(i, j int) bool { return fds[i].FD < fds[j].FD })
+	return fds
+}
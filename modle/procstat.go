@@ -0,0 +1,109 @@
+/*
+ Copyright 2020  l1b0k
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package modle
+
+import (
+	"io/ioutil"
+	"os/user"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/c9s/goprocinfo/linux"
+)
+
+// clockTicks is USER_HZ, the unit of times in /proc/<pid>/stat. It is 100 on all mainstream architectures.
+const clockTicks = 100
+
+// procSampleTTL is how long the cpu sample of an exited process is kept
+const procSampleTTL = 5 * time.Minute
+
+// procSample is the cpu time of a process at a time, used for CPU%
+type procSample struct {
+	Time      time.Time
+	StartTime uint64
+	Ticks     uint64
+}
+
+// cpuPercent store the cpu time of pid as the latest sample, and return usage since the previous sample.
+// ok is false on the first sample, or when the pid is reused by another process.
+func (d *Dao) cpuPercent(pid int, stat *linux.ProcessStat, now time.Time) (percent float64, ok bool) {
+	d.sampleLock.Lock()
+	defer d.sampleLock.Unlock()
+
+	if d.procSamples == nil {
+		d.procSamples = map[int]procSample{}
+	}
+	ticks := stat.Utime + stat.Stime
+	prev, found := d.procSamples[pid]
+	d.procSamples[pid] = procSample{Time: now, StartTime: stat.Starttime, Ticks: ticks}
+	for p, s := range d.procSamples {
+		if now.Sub(s.Time) > procSampleTTL {
+			delete(d.procSamples, p)
+		}
+	}
+	if !found || prev.StartTime != stat.Starttime || ticks < prev.Ticks {
+		return 0, false
+	}
+	seconds := now.Sub(prev.Time).Seconds()
+	if seconds <= 0 {
+		return 0, false
+	}
+	return float64(ticks-prev.Ticks) / clockTicks / seconds * 100, true
+}
+
+// bootTime return when the host booted, read from stat under procfs root once
+func (d *Dao) bootTime() time.Time {
+	d.bootOnce.Do(func() {
+		stat, err := linux.ReadStat(filepath.Join(d.ProcRoot, "stat"))
+		if err == nil {
+			d.boot = stat.BootTime
+		}
+	})
+	return d.boot
+}
+
+// countFDs return the number of open files of pid, -1 if it is not readable
+func countFDs(root string, pid int) int {
+	fds, err := ioutil.ReadDir(procPath(root, pid, "fd"))
+	if err != nil {
+		return -1
+	}
+	return len(fds)
+}
+
+var (
+	userNames    = map[uint64]string{}
+	userNameLock sync.Mutex
+)
+
+// userName return the name of uid from the local user database, or uid if not found
+func userName(uid uint64) string {
+	userNameLock.Lock()
+	defer userNameLock.Unlock()
+	if name, ok := userNames[uid]; ok {
+		return name
+	}
+	id := strconv.FormatUint(uid, 10)
+	name := id
+	if u, err := user.LookupId(id); err == nil {
+		name = u.Username
+	}
+	userNames[uid] = name
+	return name
+}
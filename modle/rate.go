@@ -0,0 +1,103 @@
+/*
+ Copyright 2020  l1b0k
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package modle
+
+import (
+	"sync"
+	"time"
+
+	"github.com/c9s/goprocinfo/linux"
+)
+
+// netSampleTTL is how long the sample of an interface no longer seen is kept
+const netSampleTTL = 5 * time.Minute
+
+// netSample is a snapshot of /proc/net/dev counters of an interface
+type netSample struct {
+	Time time.Time
+	Stat linux.NetworkStat
+}
+
+// NetRate is per-second delta of interface counters between two samples
+type NetRate struct {
+	RxBytes   float64 `json:"rxBytes"`
+	TxBytes   float64 `json:"txBytes"`
+	RxPackets float64 `json:"rxPackets"`
+	TxPackets float64 `json:"txPackets"`
+	RxErrs    float64 `json:"rxErrs"`
+	RxDrop    float64 `json:"rxDrop"`
+	TxErrs    float64 `json:"txErrs"`
+	TxDrop    float64 `json:"txDrop"`
+}
+
+// netRates hold the latest sample of each interface, keyed by ns/name. Each reader of rates keep its own,
+// so a snapshot does not shorten the interval of the rates on screen.
+type netRates struct {
+	lock    sync.Mutex
+	samples map[string]netSample
+}
+
+// rate store stat as the latest sample of the interface in ns, and return rate since previous sample.
+// ok is false on the first sample or when counters went backwards, e.g. interface recreated.
+func (r *netRates) rate(ns string, stat linux.NetworkStat, now time.Time) (rate NetRate, ok bool) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if r.samples == nil {
+		r.samples = map[string]netSample{}
+	}
+	key := ns + "/" + stat.Iface
+	prev, found := r.samples[key]
+	r.samples[key] = netSample{Time: now, Stat: stat}
+	for k, s := range r.samples {
+		if now.Sub(s.Time) > netSampleTTL {
+			delete(r.samples, k)
+		}
+	}
+	if !found {
+		return rate, false
+	}
+	seconds := now.Sub(prev.Time).Seconds()
+	if seconds <= 0 {
+		return rate, false
+	}
+
+	cur, old := []uint64{
+		stat.RxBytes, stat.TxBytes, stat.RxPackets, stat.TxPackets,
+		stat.RxErrs, stat.RxDrop, stat.TxErrs, stat.TxDrop,
+	}, []uint64{
+		prev.Stat.RxBytes, prev.Stat.TxBytes, prev.Stat.RxPackets, prev.Stat.TxPackets,
+		prev.Stat.RxErrs, prev.Stat.RxDrop, prev.Stat.TxErrs, prev.Stat.TxDrop,
+	}
+	delta := make([]float64, len(cur))
+	for i := range cur {
+		if cur[i] < old[i] {
+			return rate, false
+		}
+		delta[i] = float64(cur[i]-old[i]) / seconds
+	}
+	return NetRate{
+		RxBytes:   delta[0],
+		TxBytes:   delta[1],
+		RxPackets: delta[2],
+		TxPackets: delta[3],
+		RxErrs:    delta[4],
+		RxDrop:    delta[5],
+		TxErrs:    delta[6],
+		TxDrop:    delta[7],
+	}, true
+}
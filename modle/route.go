@@ -0,0 +1,319 @@
+/*
+ Copyright 2020  l1b0k
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package modle
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+
+	"github.com/vishvananda/netlink"
+	"golang.org/x/sys/unix"
+)
+
+// names as iproute2 print them, see /etc/iproute2/rt_tables and rt_protos
+var (
+	routeTables = map[int]string{
+		unix.RT_TABLE_DEFAULT: "default",
+		unix.RT_TABLE_MAIN:    "main",
+		unix.RT_TABLE_LOCAL:   "local",
+	}
+	routeProtocols = map[int]string{
+		unix.RTPROT_UNSPEC:   "unspec",
+		unix.RTPROT_REDIRECT: "redirect",
+		unix.RTPROT_KERNEL:   "kernel",
+		unix.RTPROT_BOOT:     "boot",
+		unix.RTPROT_STATIC:   "static",
+		unix.RTPROT_RA:       "ra",
+		unix.RTPROT_DHCP:     "dhcp",
+		unix.RTPROT_BIRD:     "bird",
+	}
+	routeScopes = map[netlink.Scope]string{
+		netlink.SCOPE_UNIVERSE: "global",
+		netlink.SCOPE_SITE:     "site",
+		netlink.SCOPE_LINK:     "link",
+		netlink.SCOPE_HOST:     "host",
+		netlink.SCOPE_NOWHERE:  "nowhere",
+	}
+	routeTypes = map[int]string{
+		unix.RTN_UNICAST:     "unicast",
+		unix.RTN_LOCAL:       "local",
+		unix.RTN_BROADCAST:   "broadcast",
+		unix.RTN_ANYCAST:     "anycast",
+		unix.RTN_MULTICAST:   "multicast",
+		unix.RTN_BLACKHOLE:   "blackhole",
+		unix.RTN_UNREACHABLE: "unreachable",
+		unix.RTN_PROHIBIT:    "prohibit",
+		unix.RTN_THROW:       "throw",
+		unix.RTN_NAT:         "nat",
+	}
+	neighStates = []struct {
+		State int
+		Name  string
+	}{
+		{netlink.NUD_INCOMPLETE, "INCOMPLETE"},
+		{netlink.NUD_REACHABLE, "REACHABLE"},
+		{netlink.NUD_STALE, "STALE"},
+		{netlink.NUD_DELAY, "DELAY"},
+		{netlink.NUD_PROBE, "PROBE"},
+		{netlink.NUD_FAILED, "FAILED"},
+		{netlink.NUD_NOARP, "NOARP"},
+		{netlink.NUD_PERMANENT, "PERMANENT"},
+	}
+)
+
+// Route is a route of any table, named as iproute2 print it
+type Route struct {
+	Table string `json:"table"`
+	Dst   string `json:"dst"`
+	// Gateways and Devs have one entry per next hop of a multipath route
+	Gateways []string `json:"gateways"`
+	Devs     []string `json:"devs"`
+	Src      string   `json:"src"`
+	Protocol string   `json:"protocol"`
+	Scope    string   `json:"scope"`
+	Type     string   `json:"type"`
+	Metric   int      `json:"metric"`
+}
+
+// Rule is a policy routing rule
+type Rule struct {
+	Family   string `json:"family"`
+	Priority int    `json:"priority"`
+	From     string `json:"from"`
+	To       string `json:"to"`
+	IIF      string `json:"iif"`
+	OIF      string `json:"oif"`
+	FWMark   string `json:"fwmark"`
+	Table    string `json:"table"`
+}
+
+// Neigh is an entry of the neighbor table
+type Neigh struct {
+	IP     string   `json:"ip"`
+	LLAddr string   `json:"lladdr"`
+	Dev    string   `json:"dev"`
+	State  []string `json:"state"`
+}
+
+// Routing is the routes, policy rules and neighbors of a net namespace
+type Routing struct {
+	Routes []Route
+	Rules  []Rule
+	Neighs []Neigh
+}
+
+// GetRouteDetail return routes of all tables and families in the net namespace
+func (d *Dao) GetRouteDetail(ns string) []Route {
+	var data []Route
+	if d.replayTable(ns, tableRoutes, &data) {
+		return data
+	}
+	_ = d.doInNetNS(ns, func() error {
+		data = listRoutes(linkNames())
+		return nil
+	})
+	return data
+}
+
+// GetRuleDetail return policy routing rules of all families in the net namespace
+func (d *Dao) GetRuleDetail(ns string) []Rule {
+	var data []Rule
+	if d.replayTable(ns, tableRules, &data) {
+		return data
+	}
+	_ = d.doInNetNS(ns, func() error {
+		data = listRules()
+		return nil
+	})
+	return data
+}
+
+// GetNeighDetail return neighbor table of all families in the net namespace
+func (d *Dao) GetNeighDetail(ns string) []Neigh {
+	var data []Neigh
+	if d.replayTable(ns, tableNeigh, &data) {
+		return data
+	}
+	_ = d.doInNetNS(ns, func() error {
+		data = listNeighs(linkNames())
+		return nil
+	})
+	return data
+}
+
+// GetRoutingDetail return routes, rules and neighbors of the net namespace, read in a single visit of it
+func (d *Dao) GetRoutingDetail(ns string) Routing {
+	var data Routing
+	if d.replayTable(ns, tableRoutes, &data.Routes) {
+		d.replayTable(ns, tableRules, &data.Rules)
+		d.replayTable(ns, tableNeigh, &data.Neighs)
+		return data
+	}
+	_ = d.doInNetNS(ns, func() error {
+		names := linkNames()
+		data.Routes = listRoutes(names)
+		data.Rules = listRules()
+		data.Neighs = listNeighs(names)
+		return nil
+	})
+	return data
+}
+
+// listRoutes return routes of all tables and families in current net namespace, names map ifindex to name
+func listRoutes(names map[int]string) []Route {
+	var data []Route
+	routes, err := netlink.RouteListFiltered(netlink.FAMILY_ALL, &netlink.Route{Table: unix.RT_TABLE_UNSPEC}, netlink.RT_FILTER_TABLE)
+	if err != nil {
+		return data
+	}
+	for _, r := range routes {
+		dst := "default"
+		if r.Dst != nil {
+			dst = r.Dst.String()
+		}
+		var gw, dev []string
+		if len(r.MultiPath) > 0 {
+			for _, h := range r.MultiPath {
+				gw = append(gw, ipString(h.Gw))
+				dev = append(dev, names[h.LinkIndex])
+			}
+		} else {
+			gw = append(gw, ipString(r.Gw))
+			dev = append(dev, names[r.LinkIndex])
+		}
+		data = append(data, Route{
+			Table:    nameOr(routeTables, r.Table),
+			Dst:      dst,
+			Gateways: gw,
+			Devs:     dev,
+			Src:      ipString(r.Src),
+			Protocol: nameOr(routeProtocols, r.Protocol),
+			Scope:    routeScopes[r.Scope],
+			Type:     nameOr(routeTypes, r.Type),
+			Metric:   r.Priority,
+		})
+	}
+	return data
+}
+
+// listRules return policy routing rules of all families in current net namespace
+func listRules() []Rule {
+	var data []Rule
+	// library does not fill Rule.Family, list each family on its own
+	for _, family := range []int{netlink.FAMILY_V4, netlink.FAMILY_V6} {
+		rules, err := netlink.RuleList(family)
+		if err != nil {
+			continue
+		}
+		name := "inet"
+		if family == netlink.FAMILY_V6 {
+			name = "inet6"
+		}
+		for _, r := range rules {
+			data = append(data, formatRule(name, r))
+		}
+	}
+	return data
+}
+
+func formatRule(family string, r netlink.Rule) Rule {
+	from, to := "all", "all"
+	if r.Src != nil {
+		from = r.Src.String()
+	}
+	if r.Dst != nil {
+		to = r.Dst.String()
+	}
+	if r.Invert {
+		from = "not " + from
+	}
+	mark := ""
+	if r.Mark > 0 {
+		mark = fmt.Sprintf("0x%x", r.Mark)
+		if r.Mask > 0 && r.Mask != 0xffffffff {
+			mark += fmt.Sprintf("/0x%x", r.Mask)
+		}
+	}
+	// priority 0 is not carried by netlink, library leave it -1
+	prio := r.Priority
+	if prio < 0 {
+		prio = 0
+	}
+	return Rule{
+		Family:   family,
+		Priority: prio,
+		From:     from,
+		To:       to,
+		IIF:      r.IifName,
+		OIF:      r.OifName,
+		FWMark:   mark,
+		Table:    nameOr(routeTables, r.Table),
+	}
+}
+
+// listNeighs return neighbor table of all families in current net namespace, names map ifindex to name
+func listNeighs(names map[int]string) []Neigh {
+	var data []Neigh
+	neighs, err := netlink.NeighList(0, netlink.FAMILY_ALL)
+	if err != nil {
+		return data
+	}
+	for _, n := range neighs {
+		var state []string
+		for _, s := range neighStates {
+			if n.State&s.State != 0 {
+				state = append(state, s.Name)
+			}
+		}
+		data = append(data, Neigh{
+			IP:     ipString(n.IP),
+			LLAddr: n.HardwareAddr.String(),
+			Dev:    names[n.LinkIndex],
+			State:  state,
+		})
+	}
+	return data
+}
+
+// linkNames map ifindex to name in current net namespace
+func linkNames() map[int]string {
+	names := map[int]string{}
+	links, err := netlink.LinkList()
+	if err != nil {
+		return names
+	}
+	for _, l := range links {
+		names[l.Attrs().Index] = l.Attrs().Name
+	}
+	return names
+}
+
+func ipString(ip net.IP) string {
+	if ip == nil {
+		return ""
+	}
+	return ip.String()
+}
+
+// nameOr return the name of v, or v itself when unknown
+func nameOr(names map[int]string, v int) string {
+	if name, ok := names[v]; ok {
+		return name
+	}
+	return strconv.Itoa(v)
+}
@@ -0,0 +1,84 @@
+/*
+ Copyright 2020  l1b0k
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package modle
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// default sockets probed in order when no endpoint is configured
+var defaultEndpoints = []string{
+	"/var/run/docker.sock",
+	"/run/containerd/containerd.sock",
+	"/var/run/crio/crio.sock",
+}
+
+// Runtime is a container runtime volans can ask for containers
+type Runtime interface {
+	// Name of the runtime, e.g. docker
+	Name() string
+	// ListContainers return running pod sandboxes and containers, Pid is the init process on host
+	ListContainers(ctx context.Context) ([]Container, error)
+	Close() error
+}
+
+// NewRuntime create runtime by socket path, docker.sock use docker api, containerd.sock use containerd api,
+// other sockets are treated as a CRI runtime service, such as cri-o.
+// Empty endpoint probe defaultEndpoints. The runtime is connected at its first call.
+func NewRuntime(endpoint string) (Runtime, error) {
+	if endpoint != "" {
+		if _, err := os.Stat(strings.TrimPrefix(endpoint, "unix://")); err != nil {
+			return nil, err
+		}
+	} else {
+		for _, e := range defaultEndpoints {
+			if _, err := os.Stat(e); err == nil {
+				endpoint = e
+				break
+			}
+		}
+		if endpoint == "" {
+			return nil, fmt.Errorf("no container runtime socket found")
+		}
+	}
+	path := strings.TrimPrefix(endpoint, "unix://")
+
+	// returned one by one, a nil pointer in a Runtime is not a nil Runtime
+	switch {
+	case strings.HasSuffix(path, "docker.sock"):
+		r, err := NewDockerRuntime(path)
+		if err != nil {
+			return nil, err
+		}
+		return r, nil
+	case strings.HasSuffix(path, "containerd.sock"):
+		r, err := NewContainerdRuntime(path)
+		if err != nil {
+			return nil, err
+		}
+		return r, nil
+	default:
+		r, err := NewCRIRuntime(path)
+		if err != nil {
+			return nil, err
+		}
+		return r, nil
+	}
+}
@@ -0,0 +1,89 @@
+/*
+ Copyright 2020  l1b0k
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package modle
+
+import (
+	"fmt"
+	"os"
+	"runtime"
+
+	"golang.org/x/sys/unix"
+)
+
+// nsCloneFlags are the namespace types a multi-threaded process is allowed to setns into
+var nsCloneFlags = map[string]int{
+	"cgroup": unix.CLONE_NEWCGROUP,
+	"ipc":    unix.CLONE_NEWIPC,
+	"net":    unix.CLONE_NEWNET,
+	"uts":    unix.CLONE_NEWUTS,
+}
+
+// doInNS run fn on a dedicated OS thread which has joined the nsType namespace of pid.
+// The thread is switched back afterwards, or discarded if that fails.
+func (d *Dao) doInNS(pid int, nsType string, fn func() error) error {
+	flag, ok := nsCloneFlags[nsType]
+	if !ok {
+		return fmt.Errorf("setns into %s namespace is not supported", nsType)
+	}
+	target, err := os.Open(d.procPath(pid, "ns", nsType))
+	if err != nil {
+		return err
+	}
+	defer target.Close()
+
+	errCh := make(chan error, 1)
+	go func() {
+		runtime.LockOSThread()
+
+		// the thread of volans itself, always in the local procfs
+		origin, err := os.Open(fmt.Sprintf("/proc/thread-self/ns/%s", nsType))
+		if err != nil {
+			runtime.UnlockOSThread()
+			errCh <- err
+			return
+		}
+		defer origin.Close()
+
+		err = unix.Setns(int(target.Fd()), flag)
+		if err != nil {
+			runtime.UnlockOSThread()
+			errCh <- fmt.Errorf("setns %s, %w", nsType, err)
+			return
+		}
+
+		err = fn()
+
+		restoreErr := unix.Setns(int(origin.Fd()), flag)
+		if restoreErr != nil {
+			// keep the thread locked, runtime will terminate it when goroutine exit
+			errCh <- fmt.Errorf("restore %s namespace, %w", nsType, restoreErr)
+			return
+		}
+		runtime.UnlockOSThread()
+		errCh <- err
+	}()
+	return <-errCh
+}
+
+// doInNetNS run fn inside the net namespace ns, entered through one of its processes by doInNS
+func (d *Dao) doInNetNS(ns string, fn func() error) error {
+	pids := d.GetPIDs(ns)
+	if len(pids) == 0 {
+		return fmt.Errorf("no process in namespace %s", ns)
+	}
+	return d.doInNS(pids[0], "net", fn)
+}
@@ -0,0 +1,337 @@
+/*
+ Copyright 2020  l1b0k
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package modle
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"strings"
+	"time"
+)
+
+// SnapshotVersion is the format version written into snapshots
+const SnapshotVersion = 1
+
+// snapshotRateInterval is the time between the two samples of interface counters, so rates are kept
+const snapshotRateInterval = time.Second
+
+// names of tables kept in a snapshot, one per Get*Detail method
+const (
+	tableCgroup  = "cgroup"
+	tableIPC     = "ipc"
+	tableMounts  = "mounts"
+	tableUser    = "idmap"
+	tableUTS     = "uts"
+	tableSockets = "sockets"
+	tableRoutes  = "routes"
+	tableRules   = "rules"
+	tableNeigh   = "neigh"
+)
+
+// SnapshotMeta describe where and when a snapshot is captured
+type SnapshotMeta struct {
+	Version  int       `json:"version"`
+	Hostname string    `json:"hostname"`
+	Time     time.Time `json:"time"`
+}
+
+// Snapshot is everything Dao collects of a node, so it can be browsed offline
+type Snapshot struct {
+	Meta       SnapshotMeta
+	Procs      []Proc
+	Containers []Container
+	// Processes of all pids in Procs
+	Processes []Process
+	// Interfaces of each net namespace
+	Interfaces map[string][]Interface
+	// Tables of each namespace, keyed by table name such as "mounts". Rows are json of the Get*Detail result,
+	// decoded into its type on replay.
+	Tables map[string]map[string]json.RawMessage
+}
+
+// snapshotTables return the tables captured for a namespace of nsType
+func (d *Dao) snapshotTables(nsType string) map[string]func(ns string) interface{} {
+	switch nsType {
+	case "cgroup":
+		return map[string]func(ns string) interface{}{
+			tableCgroup: func(ns string) interface{} { return d.GetCgroupDetail(ns) },
+		}
+	case "ipc":
+		return map[string]func(ns string) interface{}{
+			tableIPC: func(ns string) interface{} { return d.GetIPCDetail(ns) },
+		}
+	case "mnt":
+		return map[string]func(ns string) interface{}{
+			tableMounts: func(ns string) interface{} { return d.GetMountDetail(ns) },
+		}
+	case "net":
+		return map[string]func(ns string) interface{}{
+			tableSockets: func(ns string) interface{} { return d.GetSocketDetail(ns) },
+			tableRoutes:  func(ns string) interface{} { return d.GetRouteDetail(ns) },
+			tableRules:   func(ns string) interface{} { return d.GetRuleDetail(ns) },
+			tableNeigh:   func(ns string) interface{} { return d.GetNeighDetail(ns) },
+		}
+	case "user":
+		return map[string]func(ns string) interface{}{
+			tableUser: func(ns string) interface{} { return d.GetUserDetail(ns) },
+		}
+	case "uts":
+		return map[string]func(ns string) interface{}{
+			tableUTS: func(ns string) interface{} { return d.GetUTSDetail(ns) },
+		}
+	}
+	return nil
+}
+
+// Snapshot collect the current state. Interface counters are sampled twice, so it takes about a second.
+func (d *Dao) Snapshot() (*Snapshot, error) {
+	if d.snapshot != nil {
+		return d.snapshot, nil
+	}
+	d.Run()
+	d.LoadProcData()
+
+	hostname, _ := os.Hostname()
+	s := &Snapshot{
+		Meta: SnapshotMeta{
+			Version:  SnapshotVersion,
+			Hostname: hostname,
+		},
+		Interfaces: map[string][]Interface{},
+		Tables:     map[string]map[string]json.RawMessage{},
+	}
+	if err := d.DB.Find(&s.Procs).Error; err != nil {
+		return nil, err
+	}
+	if err := d.DB.Find(&s.Containers).Error; err != nil {
+		return nil, err
+	}
+
+	namespaces := d.GetNSWithPidCount()
+	// first sample of counters, kept apart from the samples of the live view
+	rates := &netRates{}
+	for _, n := range namespaces {
+		if n.Type == "net" {
+			_, _ = d.netNSDetail(n.Inode, rates)
+		}
+	}
+	time.Sleep(snapshotRateInterval)
+
+	s.Meta.Time = time.Now()
+	for _, n := range namespaces {
+		if n.Type == "net" {
+			// a namespace gone during the snapshot is kept without interfaces
+			s.Interfaces[n.Inode], _ = d.netNSDetail(n.Inode, rates)
+		}
+		for name, fn := range d.snapshotTables(n.Type) {
+			rows, err := json.Marshal(fn(n.Inode))
+			if err != nil {
+				return nil, fmt.Errorf("%s of %s: %w", name, n.Inode, err)
+			}
+			if s.Tables[n.Inode] == nil {
+				s.Tables[n.Inode] = map[string]json.RawMessage{}
+			}
+			s.Tables[n.Inode][name] = rows
+		}
+	}
+	// pid namespace hold every process once
+	for _, n := range namespaces {
+		if n.Type == "pid" {
+			s.Processes = append(s.Processes, d.GetProcDetail(n.Inode)...)
+		}
+	}
+	return s, nil
+}
+
+// Replay return meta of the snapshot being replayed, nil if Dao read the live system
+func (d *Dao) Replay() *SnapshotMeta {
+	if d.snapshot == nil {
+		return nil
+	}
+	return &d.snapshot.Meta
+}
+
+// replayTable decode the table of ns from the snapshot into rows, a pointer to the slice Get*Detail return.
+// It return false if Dao read the live system.
+func (d *Dao) replayTable(ns, name string, rows interface{}) bool {
+	if d.snapshot == nil {
+		return false
+	}
+	if b, ok := d.snapshot.Tables[ns][name]; ok {
+		// checked by ReadSnapshot to be json, a table of another type is shown empty
+		_ = json.Unmarshal(b, rows)
+	}
+	return true
+}
+
+// loadSnapshot fill db with the index of the snapshot
+func (d *Dao) loadSnapshot(s *Snapshot) error {
+	d.snapshot = s
+	if len(s.Procs) > 0 {
+		if err := d.DB.CreateInBatches(s.Procs, 100).Error; err != nil {
+			return err
+		}
+	}
+	if len(s.Containers) > 0 {
+		if err := d.DB.CreateInBatches(s.Containers, 100).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// WriteSnapshot write s to w as tar.gz, each part is a json file:
+// volans.json, procs.json, containers.json, processes.json, and ns/<inode>/<table>.json
+func WriteSnapshot(w io.Writer, s *Snapshot) error {
+	gw := gzip.NewWriter(w)
+	tw := tar.NewWriter(gw)
+
+	write := func(name string, v interface{}) error {
+		b, err := json.MarshalIndent(v, "", "  ")
+		if err != nil {
+			return err
+		}
+		err = tw.WriteHeader(&tar.Header{
+			Name:    name,
+			Mode:    0644,
+			Size:    int64(len(b)),
+			ModTime: s.Meta.Time,
+		})
+		if err != nil {
+			return err
+		}
+		_, err = tw.Write(b)
+		return err
+	}
+
+	if err := write("volans.json", s.Meta); err != nil {
+		return err
+	}
+	if err := write("procs.json", s.Procs); err != nil {
+		return err
+	}
+	if err := write("containers.json", s.Containers); err != nil {
+		return err
+	}
+	if err := write("processes.json", s.Processes); err != nil {
+		return err
+	}
+	for ns, ifaces := range s.Interfaces {
+		if err := write(path.Join("ns", ns, "interfaces.json"), ifaces); err != nil {
+			return err
+		}
+	}
+	for ns, tables := range s.Tables {
+		for name, rows := range tables {
+			if err := write(path.Join("ns", ns, name+".json"), rows); err != nil {
+				return err
+			}
+		}
+	}
+
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	return gw.Close()
+}
+
+// ReadSnapshotFile read a snapshot file written by WriteSnapshot
+func ReadSnapshotFile(path string) (*Snapshot, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return ReadSnapshot(f)
+}
+
+// ReadSnapshot read a snapshot written by WriteSnapshot
+func ReadSnapshot(r io.Reader) (*Snapshot, error) {
+	gr, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, err
+	}
+	defer gr.Close()
+
+	s := &Snapshot{
+		Interfaces: map[string][]Interface{},
+		Tables:     map[string]map[string]json.RawMessage{},
+	}
+	tr := tar.NewReader(gr)
+	for {
+		h, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		b, err := ioutil.ReadAll(tr)
+		if err != nil {
+			return nil, err
+		}
+
+		name := path.Clean(h.Name)
+		var v interface{}
+		switch name {
+		case "volans.json":
+			v = &s.Meta
+		case "procs.json":
+			v = &s.Procs
+		case "containers.json":
+			v = &s.Containers
+		case "processes.json":
+			v = &s.Processes
+		default:
+			// ns/<inode>/<table>.json
+			parts := strings.Split(name, "/")
+			if len(parts) != 3 || parts[0] != "ns" || !strings.HasSuffix(parts[2], ".json") {
+				continue
+			}
+			ns, table := parts[1], strings.TrimSuffix(parts[2], ".json")
+			if table == "interfaces" {
+				var ifaces []Interface
+				if err := json.Unmarshal(b, &ifaces); err != nil {
+					return nil, fmt.Errorf("%s: %w", name, err)
+				}
+				s.Interfaces[ns] = ifaces
+				continue
+			}
+			if !json.Valid(b) {
+				return nil, fmt.Errorf("%s: invalid json", name)
+			}
+			if s.Tables[ns] == nil {
+				s.Tables[ns] = map[string]json.RawMessage{}
+			}
+			s.Tables[ns][table] = b
+			continue
+		}
+		if err := json.Unmarshal(b, v); err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+	}
+	if s.Meta.Version != SnapshotVersion {
+		return nil, fmt.Errorf("unsupported snapshot version %d", s.Meta.Version)
+	}
+	return s, nil
+}
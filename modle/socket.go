@@ -0,0 +1,333 @@
+/*
+ Copyright 2020  l1b0k
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package modle
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/vishvananda/netlink/nl"
+	"golang.org/x/sys/unix"
+)
+
+// sock_diag constants, see linux/inet_diag.h and linux/unix_diag.h
+const (
+	sizeofInetDiagReq = 56
+	sizeofInetDiagMsg = 72
+	sizeofUnixDiagReq = 24
+	sizeofUnixDiagMsg = 16
+
+	udiagShowName  = 0x01
+	udiagShowPeer  = 0x04
+	udiagShowRqlen = 0x10
+
+	unixDiagName  = 0
+	unixDiagPeer  = 2
+	unixDiagRqlen = 4
+)
+
+// tcpStates is the name of sk_state, same as ss
+var tcpStates = map[uint8]string{
+	1:  "ESTAB",
+	2:  "SYN-SENT",
+	3:  "SYN-RECV",
+	4:  "FIN-WAIT-1",
+	5:  "FIN-WAIT-2",
+	6:  "TIME-WAIT",
+	7:  "UNCONN",
+	8:  "CLOSE-WAIT",
+	9:  "LAST-ACK",
+	10: "LISTEN",
+	11: "CLOSING",
+	12: "NEW-SYN-RECV",
+}
+
+var unixTypes = map[uint8]string{
+	unix.SOCK_STREAM:    "u_str",
+	unix.SOCK_DGRAM:     "u_dgr",
+	unix.SOCK_SEQPACKET: "u_seq",
+}
+
+// Socket is a socket dumped by sock_diag
+type Socket struct {
+	Proto  string `json:"proto"`
+	Local  string `json:"local"`
+	Remote string `json:"remote"`
+	State  string `json:"state"`
+	RecvQ  uint32 `json:"recv_q"`
+	SendQ  uint32 `json:"send_q"`
+	Inode  uint32 `json:"inode"`
+	// PIDs hold the socket open, filled by GetSocketDetail
+	PIDs []int `json:"pids"`
+}
+
+// inetDiagReq is struct inet_diag_req_v2 with an empty socket id, used for dump
+type inetDiagReq struct {
+	Family   uint8
+	Protocol uint8
+	States   uint32
+}
+
+func (r *inetDiagReq) Serialize() []byte {
+	b := make([]byte, sizeofInetDiagReq)
+	b[0] = r.Family
+	b[1] = r.Protocol
+	nl.NativeEndian().PutUint32(b[4:8], r.States)
+	// cookie INET_DIAG_NOCOOKIE
+	nl.NativeEndian().PutUint32(b[48:52], ^uint32(0))
+	nl.NativeEndian().PutUint32(b[52:56], ^uint32(0))
+	return b
+}
+
+func (r *inetDiagReq) Len() int { return sizeofInetDiagReq }
+
+// unixDiagReq is struct unix_diag_req
+type unixDiagReq struct {
+	States uint32
+	Show   uint32
+}
+
+func (r *unixDiagReq) Serialize() []byte {
+	b := make([]byte, sizeofUnixDiagReq)
+	b[0] = unix.AF_UNIX
+	nl.NativeEndian().PutUint32(b[4:8], r.States)
+	nl.NativeEndian().PutUint32(b[12:16], r.Show)
+	// cookie INET_DIAG_NOCOOKIE
+	nl.NativeEndian().PutUint32(b[16:20], ^uint32(0))
+	nl.NativeEndian().PutUint32(b[20:24], ^uint32(0))
+	return b
+}
+
+func (r *unixDiagReq) Len() int { return sizeofUnixDiagReq }
+
+// GetSocketDetail return tcp, udp and unix sockets of the net namespace, with the pids holding them
+func (d *Dao) GetSocketDetail(ns string) []Socket {
+	var sockets []Socket
+	if d.replayTable(ns, tableSockets, &sockets) {
+		return sockets
+	}
+	pids := d.GetPIDs(ns)
+	if len(pids) == 0 {
+		return nil
+	}
+
+	sockets, err := d.dumpSockets(pids[0])
+	if err != nil {
+		return nil
+	}
+	owners := socketOwners(d.ProcRoot, pids)
+	for i := range sockets {
+		sockets[i].PIDs = owners[sockets[i].Inode]
+	}
+	return sockets
+}
+
+// dumpSockets dump tcp, udp and unix sockets in the net namespace of pid
+// a protocol failing to dump, such as when its diag module is not loaded, is skipped
+func (d *Dao) dumpSockets(pid int) ([]Socket, error) {
+	var sockets []Socket
+	err := d.doInNS(pid, "net", func() error {
+		for _, family := range []uint8{unix.AF_INET, unix.AF_INET6} {
+			for _, proto := range []uint8{unix.IPPROTO_TCP, unix.IPPROTO_UDP} {
+				if s, err := inetDiagDump(family, proto); err == nil {
+					sockets = append(sockets, s...)
+				}
+			}
+		}
+		if s, err := unixDiagDump(); err == nil {
+			sockets = append(sockets, s...)
+		}
+		return nil
+	})
+	return sockets, err
+}
+
+// inetDiagDump dump all sockets of family and protocol in current net namespace
+func inetDiagDump(family, proto uint8) ([]Socket, error) {
+	req := nl.NewNetlinkRequest(nl.SOCK_DIAG_BY_FAMILY, unix.NLM_F_DUMP)
+	req.AddData(&inetDiagReq{
+		Family:   family,
+		Protocol: proto,
+		States:   ^uint32(0),
+	})
+	msgs, err := req.Execute(unix.NETLINK_SOCK_DIAG, nl.SOCK_DIAG_BY_FAMILY)
+	if err != nil {
+		return nil, err
+	}
+
+	name := "tcp"
+	if proto == unix.IPPROTO_UDP {
+		name = "udp"
+	}
+	if family == unix.AF_INET6 {
+		name += "6"
+	}
+	var sockets []Socket
+	for _, m := range msgs {
+		s, err := parseInetDiagMsg(m)
+		if err != nil {
+			continue
+		}
+		s.Proto = name
+		sockets = append(sockets, s)
+	}
+	return sockets, nil
+}
+
+// parseInetDiagMsg parse struct inet_diag_msg
+func parseInetDiagMsg(b []byte) (Socket, error) {
+	var s Socket
+	if len(b) < sizeofInetDiagMsg {
+		return s, fmt.Errorf("inet_diag_msg short read %d", len(b))
+	}
+	native := nl.NativeEndian()
+	family := b[0]
+	s.State = tcpStates[b[1]]
+	sport := binary.BigEndian.Uint16(b[4:6])
+	dport := binary.BigEndian.Uint16(b[6:8])
+	var src, dst net.IP
+	if family == unix.AF_INET6 {
+		src = net.IP(b[8:24])
+		dst = net.IP(b[24:40])
+	} else {
+		src = net.IP(b[8:12])
+		dst = net.IP(b[24:28])
+	}
+	s.Local = net.JoinHostPort(src.String(), strconv.Itoa(int(sport)))
+	s.Remote = net.JoinHostPort(dst.String(), strconv.Itoa(int(dport)))
+	if dport == 0 && dst.IsUnspecified() {
+		s.Remote = "*"
+	}
+	s.RecvQ = native.Uint32(b[56:60])
+	s.SendQ = native.Uint32(b[60:64])
+	s.Inode = native.Uint32(b[68:72])
+	return s, nil
+}
+
+// unixDiagDump dump all unix sockets in current net namespace
+func unixDiagDump() ([]Socket, error) {
+	req := nl.NewNetlinkRequest(nl.SOCK_DIAG_BY_FAMILY, unix.NLM_F_DUMP)
+	req.AddData(&unixDiagReq{
+		States: ^uint32(0),
+		Show:   udiagShowName | udiagShowPeer | udiagShowRqlen,
+	})
+	msgs, err := req.Execute(unix.NETLINK_SOCK_DIAG, nl.SOCK_DIAG_BY_FAMILY)
+	if err != nil {
+		return nil, err
+	}
+
+	var sockets []Socket
+	for _, m := range msgs {
+		s, err := parseUnixDiagMsg(m)
+		if err != nil {
+			continue
+		}
+		sockets = append(sockets, s)
+	}
+	return sockets, nil
+}
+
+// parseUnixDiagMsg parse struct unix_diag_msg and its attributes
+func parseUnixDiagMsg(b []byte) (Socket, error) {
+	var s Socket
+	if len(b) < sizeofUnixDiagMsg {
+		return s, fmt.Errorf("unix_diag_msg short read %d", len(b))
+	}
+	native := nl.NativeEndian()
+	s.Proto = unixTypes[b[1]]
+	if s.Proto == "" {
+		s.Proto = "unix"
+	}
+	s.State = tcpStates[b[2]]
+	s.Inode = native.Uint32(b[4:8])
+	s.Local = "*"
+	s.Remote = "*"
+
+	attrs, err := nl.ParseRouteAttr(b[sizeofUnixDiagMsg:])
+	if err != nil {
+		return s, err
+	}
+	for _, attr := range attrs {
+		switch attr.Attr.Type {
+		case unixDiagName:
+			name := string(attr.Value)
+			if strings.HasPrefix(name, "\x00") {
+				// abstract socket
+				name = "@" + name[1:]
+			} else {
+				name = strings.TrimRight(name, "\x00")
+			}
+			s.Local = name
+		case unixDiagPeer:
+			if len(attr.Value) >= 4 {
+				s.Remote = fmt.Sprintf("*%d", native.Uint32(attr.Value))
+			}
+		case unixDiagRqlen:
+			if len(attr.Value) >= 8 {
+				s.RecvQ = native.Uint32(attr.Value[0:4])
+				s.SendQ = native.Uint32(attr.Value[4:8])
+			}
+		}
+	}
+	return s, nil
+}
+
+// socketOwners map socket inode to the pids hold it, by scanning /proc/<pid>/fd of pids in order
+func socketOwners(root string, pids []int) map[uint32][]int {
+	owners := map[uint32][]int{}
+	for _, pid := range pids {
+		dir := procPath(root, pid, "fd")
+		fds, err := ioutil.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+		seen := map[uint32]bool{}
+		for _, fd := range fds {
+			link, err := os.Readlink(filepath.Join(dir, fd.Name()))
+			if err != nil || !strings.HasPrefix(link, "socket:[") {
+				continue
+			}
+			inode, err := strconv.ParseUint(link[len("socket:["):len(link)-1], 10, 32)
+			if err != nil || seen[uint32(inode)] {
+				continue
+			}
+			seen[uint32(inode)] = true
+			owners[uint32(inode)] = append(owners[uint32(inode)], pid)
+		}
+	}
+	return owners
+}
+
+// readSockets map socket inode to "proto local -> remote state", dumped in the net namespace of pid
+func (d *Dao) readSockets(pid int) map[string]string {
+	sockets, _ := d.dumpSockets(pid)
+	details := make(map[string]string, len(sockets))
+	for _, s := range sockets {
+		detail := fmt.Sprintf("%s %s -> %s", s.Proto, s.Local, s.Remote)
+		if s.State != "" {
+			detail += " " + s.State
+		}
+		details[strconv.FormatUint(uint64(s.Inode), 10)] = detail
+	}
+	return details
+}
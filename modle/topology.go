@@ -0,0 +1,160 @@
+/*
+ Copyright 2020  l1b0k
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package modle
+
+import (
+	"errors"
+	"net"
+	"strconv"
+
+	"github.com/vishvananda/netlink"
+)
+
+// TopologyNode is a net namespace with its links
+type TopologyNode struct {
+	NS    string         `json:"ns"`
+	Pods  []string       `json:"pods"`
+	Links []TopologyLink `json:"links"`
+}
+
+// TopologyLink is a link of a net namespace with what it connects to
+type TopologyLink struct {
+	Name  string `json:"name"`
+	Type  string `json:"type"`
+	Index int    `json:"index"`
+	Up    bool   `json:"up"`
+	// Master is the bridge or bond the link is enslaved to, in the same namespace
+	Master string `json:"master"`
+	// Peer is the other end of a veth, Parent is the lower device of a vlan, ipvlan or macvlan,
+	// both may live in another namespace
+	Peer   *Peer `json:"peer"`
+	Parent *Peer `json:"parent"`
+	// Tunnel is the far end of a vxlan or geneve, such as "vni 1 10.0.0.2:4789"
+	Tunnel string `json:"tunnel"`
+}
+
+// topologyRef is a link referred by index in the namespace an nsid refer to, NetNsID is -1 for the same namespace
+type topologyRef struct {
+	Index   int
+	NetNsID int
+}
+
+// GetTopology list links of every net namespace, and resolve veth peers, lower devices and masters across them
+func (d *Dao) GetTopology() ([]TopologyNode, error) {
+	if d.snapshot != nil {
+		return nil, errors.New("topology is not kept in snapshots")
+	}
+	files := d.openNetNS()
+	defer files.Close()
+	var nodes []TopologyNode
+	for _, ns := range d.GetNSWithPidCount() {
+		if ns.Type == "net" {
+			nodes = append(nodes, TopologyNode{NS: ns.Inode, Pods: ns.Pods})
+		}
+	}
+
+	// links by namespace and index, with the references they hold and nsids seen from each namespace
+	names := map[string]map[int]string{}
+	peers := map[string]map[string]topologyRef{}
+	parents := map[string]map[string]topologyRef{}
+	ids := map[string]map[int]string{}
+	for i := range nodes {
+		ns := nodes[i].NS
+		names[ns] = map[int]string{}
+		peers[ns] = map[string]topologyRef{}
+		parents[ns] = map[string]topologyRef{}
+		err := d.doInNetNS(ns, func() error {
+			links, err := netlink.LinkList()
+			if err != nil {
+				return err
+			}
+			// nsids are resolved only for the ones held by links of ns
+			want := map[int]bool{}
+			for _, l := range links {
+				names[ns][l.Attrs().Index] = l.Attrs().Name
+				if id := l.Attrs().NetNsID; id >= 0 {
+					want[id] = true
+				}
+			}
+			ids[ns] = files.ids(want)
+			for _, l := range links {
+				attrs := l.Attrs()
+				link := TopologyLink{
+					Name:  attrs.Name,
+					Type:  l.Type(),
+					Index: attrs.Index,
+					Up:    attrs.Flags&net.FlagUp != 0,
+				}
+				if attrs.MasterIndex > 0 {
+					link.Master = names[ns][attrs.MasterIndex]
+				}
+				ref := topologyRef{Index: attrs.ParentIndex, NetNsID: attrs.NetNsID}
+				switch l := l.(type) {
+				case *netlink.Veth:
+					peers[ns][attrs.Name] = ref
+				case *netlink.Vlan, *netlink.IPVlan, *netlink.Macvlan, *netlink.Macvtap:
+					if attrs.ParentIndex > 0 {
+						parents[ns][attrs.Name] = ref
+					}
+				case *netlink.Vxlan:
+					link.Tunnel = "vni " + strconv.Itoa(l.VxlanId)
+					if l.Group != nil {
+						link.Tunnel += " " + net.JoinHostPort(l.Group.String(), strconv.Itoa(l.Port))
+					}
+					if l.VtepDevIndex > 0 {
+						parents[ns][attrs.Name] = topologyRef{Index: l.VtepDevIndex, NetNsID: -1}
+					}
+				default:
+					if l.Type() == "geneve" {
+						link.Tunnel, _ = geneveTunnel(attrs.Index)
+					}
+				}
+				nodes[i].Links = append(nodes[i].Links, link)
+			}
+			return nil
+		})
+		if err != nil {
+			// the namespace may be gone, it is shown without links
+			continue
+		}
+	}
+
+	resolve := func(ns string, ref topologyRef) *Peer {
+		target := ns
+		if ref.NetNsID >= 0 {
+			target = ids[ns][ref.NetNsID]
+		}
+		name, ok := names[target][ref.Index]
+		if target == "" || !ok {
+			return &Peer{NS: "?", Name: "if" + strconv.Itoa(ref.Index)}
+		}
+		return &Peer{NS: target, Name: name}
+	}
+	for i := range nodes {
+		ns := nodes[i].NS
+		for j := range nodes[i].Links {
+			link := &nodes[i].Links[j]
+			if ref, ok := peers[ns][link.Name]; ok {
+				link.Peer = resolve(ns, ref)
+			}
+			if ref, ok := parents[ns][link.Name]; ok {
+				link.Parent = resolve(ns, ref)
+			}
+		}
+	}
+	return nodes, nil
+}
@@ -0,0 +1,104 @@
+/*
+ Copyright 2020  l1b0k
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package modle
+
+import "time"
+
+// Namespace is a namespace with the pods and containers its processes belong to
+type Namespace struct {
+	Inode      string   `json:"ns"`
+	Type       string   `json:"type"`
+	NProcs     int      `json:"nprocs"`
+	Pods       []string `json:"pods"`
+	Containers []string `json:"containers"`
+}
+
+// Interface is a network interface of a net namespace
+type Interface struct {
+	Name string `json:"name"`
+	// Index is the ifindex, 0 when only /proc/net/dev know the interface
+	Index int    `json:"index"`
+	Type  string `json:"type"`
+	MAC   string `json:"mac"`
+	// Channels is nil when the driver does not report channels
+	Channels *Channels `json:"channels"`
+	IPs      []string  `json:"ips"`
+	// Rate is nil on the first sample
+	Rate *NetRate `json:"rate"`
+	// Peer is the other end of a veth, nil for other types or when it is not found
+	Peer *Peer `json:"peer"`
+	// Details is type specific attributes, such as bridge master and port state, bond mode or vlan id
+	Details []string `json:"details"`
+
+	RxBytes   uint64   `json:"rxBytes"`
+	TxBytes   uint64   `json:"txBytes"`
+	RxPackets uint64   `json:"rxPackets"`
+	TxPackets uint64   `json:"txPackets"`
+	RxErrs    uint64   `json:"rxErrs"`
+	RxDrop    uint64   `json:"rxDrop"`
+	TxErrs    uint64   `json:"txErrs"`
+	TxDrop    uint64   `json:"txDrop"`
+	MTU       int      `json:"mtu"`
+	Flags     []string `json:"flags"`
+
+	// offload features from ethtool
+	GSO        bool `json:"gso"`
+	TSO        bool `json:"tso"`
+	LRO        bool `json:"lro"`
+	GRO        bool `json:"gro"`
+	SG         bool `json:"sg"`
+	RxChecksum bool `json:"rxChecksum"`
+	TxChecksum bool `json:"txChecksum"`
+}
+
+// Channels is the combined channel count of an interface
+type Channels struct {
+	Combined    uint32 `json:"combined"`
+	MaxCombined uint32 `json:"maxCombined"`
+}
+
+// Process is a process in a namespace
+type Process struct {
+	Pid   int    `json:"pid"`
+	PPid  int    `json:"ppid"`
+	Name  string `json:"name"`
+	State string `json:"state"`
+	// CpusAllowedList and MemsAllowedList are the cpus and memory nodes the process may use as cpulist, e.g. "0-3,8"
+	CpusAllowedList string `json:"cpusAllowedList"`
+	MemsAllowedList string `json:"memsAllowedList"`
+	// LastCPU is the cpu the process last ran on, NUMANode is its node, -1 if unknown
+	LastCPU   int    `json:"lastCPU"`
+	NUMANode  int    `json:"numaNode"`
+	Pod       string `json:"pod"`
+	Container string `json:"container"`
+	Cmdline   string `json:"cmdline"`
+	// StartTime is clock ticks after boot when the process started, it tell a reused pid apart
+	StartTime uint64    `json:"startTime"`
+	StartedAt time.Time `json:"startedAt"`
+	// CPUPercent is cpu usage since the previous sample, nil on the first sample
+	CPUPercent *float64 `json:"cpuPercent"`
+	// RSS and VSZ in bytes
+	RSS     uint64 `json:"rss"`
+	VSZ     uint64 `json:"vsz"`
+	Threads int    `json:"threads"`
+	// FDs is the number of open files, -1 if it is not readable
+	FDs      int    `json:"fds"`
+	UID      int    `json:"uid"`
+	User     string `json:"user"`
+	Nice     int    `json:"nice"`
+	Priority int    `json:"priority"`
+}
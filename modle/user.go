@@ -0,0 +1,82 @@
+/*
+ Copyright 2020  l1b0k
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package modle
+
+import (
+	"bufio"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// IDMap is a line of uid_map or gid_map, ids Inside to Inside+Range-1 are Outside to Outside+Range-1 in the parent namespace
+type IDMap struct {
+	// Map is uid or gid
+	Map     string `json:"map"`
+	Inside  uint32 `json:"inside"`
+	Outside uint32 `json:"outside"`
+	Range   uint32 `json:"range"`
+}
+
+// GetUserDetail return uid and gid mapping of the user namespace
+func (d *Dao) GetUserDetail(ns string) []IDMap {
+	var data []IDMap
+	if d.replayTable(ns, tableUser, &data) {
+		return data
+	}
+	pids := d.GetPIDs(ns)
+	if len(pids) == 0 {
+		return data
+	}
+
+	for _, m := range []string{"uid", "gid"} {
+		maps, err := readIDMap(d.procPath(pids[0], m+"_map"), m)
+		if err != nil {
+			continue
+		}
+		data = append(data, maps...)
+	}
+	return data
+}
+
+// readIDMap parse /proc/<pid>/uid_map or gid_map, m tell which one
+func readIDMap(path, m string) ([]IDMap, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var maps []IDMap
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) != 3 {
+			continue
+		}
+		var ids [3]uint32
+		for i, field := range fields {
+			v, err := strconv.ParseUint(field, 10, 32)
+			if err != nil {
+				return nil, err
+			}
+			ids[i] = uint32(v)
+		}
+		maps = append(maps, IDMap{Map: m, Inside: ids[0], Outside: ids[1], Range: ids[2]})
+	}
+	return maps, scanner.Err()
+}
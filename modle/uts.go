@@ -0,0 +1,46 @@
+/*
+ Copyright 2020  l1b0k
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package modle
+
+import (
+	"golang.org/x/sys/unix"
+)
+
+// GetUTSDetail return hostname and domainname of the uts namespace
+func (d *Dao) GetUTSDetail(ns string) []KeyValue {
+	var data []KeyValue
+	if d.replayTable(ns, tableUTS, &data) {
+		return data
+	}
+	pids := d.GetPIDs(ns)
+	if len(pids) == 0 {
+		return data
+	}
+
+	var uts unix.Utsname
+	err := d.doInNS(pids[0], "uts", func() error {
+		return unix.Uname(&uts)
+	})
+	if err != nil {
+		return data
+	}
+	data = append(data,
+		KeyValue{Key: "hostname", Value: unix.ByteSliceToString(uts.Nodename[:])},
+		KeyValue{Key: "domainname", Value: unix.ByteSliceToString(uts.Domainname[:])},
+	)
+	return data
+}
@@ -0,0 +1,123 @@
+/*
+ Copyright 2020  l1b0k
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package modle
+
+import (
+	"os"
+	"strconv"
+
+	"github.com/vishvananda/netlink"
+)
+
+// Peer is the other end of a veth pair
+type Peer struct {
+	NS   string `json:"ns"`
+	Name string `json:"name"`
+}
+
+// vethLink is a veth of a net namespace, its peer is ParentIndex in the namespace NetNsID refer to,
+// NetNsID is -1 when the peer is in the same namespace
+type vethLink struct {
+	Name        string
+	ParentIndex int
+	NetNsID     int
+}
+
+// netNSPids return a pid of each net namespace
+func (d *Dao) netNSPids() map[string]int {
+	type nsPid struct {
+		Namespace string
+		Pid       int
+	}
+	var rows []nsPid
+	d.DB.Raw("select namespace, min(cast(pid as integer)) as pid from proc where ns_type = 'net' group by namespace").Scan(&rows)
+	pids := make(map[string]int, len(rows))
+	for _, r := range rows {
+		pids[r.Namespace] = r.Pid
+	}
+	return pids
+}
+
+// netNSFiles is the net namespace of each namespace, opened once to resolve nsids seen from many namespaces
+type netNSFiles map[string]*os.File
+
+// openNetNS open the net namespace of each namespace known, those can not be opened are left out
+func (d *Dao) openNetNS() netNSFiles {
+	files := netNSFiles{}
+	for ns, pid := range d.netNSPids() {
+		f, err := os.Open(d.procPath(pid, "ns", "net"))
+		if err != nil {
+			continue
+		}
+		files[ns] = f
+	}
+	return files
+}
+
+func (f netNSFiles) Close() {
+	for _, file := range f {
+		file.Close()
+	}
+}
+
+// ids map nsids in want of the current net namespace to the namespaces they refer to, it is called in that
+// namespace. It stops asking the kernel once every nsid in want is found.
+func (f netNSFiles) ids(want map[int]bool) map[int]string {
+	ids := map[int]string{}
+	for ns, file := range f {
+		if len(ids) == len(want) {
+			break
+		}
+		id, err := netlink.GetNetNsIdByFd(int(file.Fd()))
+		if err == nil && want[id] {
+			ids[id] = ns
+		}
+	}
+	return ids
+}
+
+// vethPeers resolve veths of net namespace ns to their peers, ids is the nsids seen from ns.
+// A peer whose namespace is not known is left out.
+func (d *Dao) vethPeers(ns string, veths []vethLink, ids map[int]string) map[string]*Peer {
+	peers := map[string]*Peer{}
+	// group by namespace of peers, so each is entered once
+	byNS := map[string][]vethLink{}
+	for _, v := range veths {
+		peerNS := ns
+		if v.NetNsID >= 0 {
+			peerNS = ids[v.NetNsID]
+		}
+		if peerNS == "" {
+			continue
+		}
+		byNS[peerNS] = append(byNS[peerNS], v)
+	}
+	for peerNS, vs := range byNS {
+		_ = d.doInNetNS(peerNS, func() error {
+			for _, v := range vs {
+				link, err := netlink.LinkByIndex(v.ParentIndex)
+				if err != nil {
+					peers[v.Name] = &Peer{NS: peerNS, Name: "if" + strconv.Itoa(v.ParentIndex)}
+					continue
+				}
+				peers[v.Name] = &Peer{NS: peerNS, Name: link.Attrs().Name}
+			}
+			return nil
+		})
+	}
+	return peers
+}
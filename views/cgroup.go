@@ -0,0 +1,45 @@
+/*
+ Copyright 2020  l1b0k
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package views
+
+import (
+	"strconv"
+
+	"github.com/l1b0k/volans/modle"
+	"github.com/rivo/tview"
+)
+
+// NewCgroupView show cgroup root of a cgroup namespace
+func NewCgroupView() *tview.Table {
+	view := tview.NewTable().
+		SetBorders(false).
+		SetSelectable(false, false).
+		SetFixed(1, 0)
+	view.SetBorder(true).SetTitle("cgroup")
+	return view
+}
+
+// CgroupFields is the columns of modle.CgroupRoot
+func CgroupFields() []Field {
+	root := func(row interface{}) modle.CgroupRoot { return row.(modle.CgroupRoot) }
+	return []Field{
+		{Text: "ID", Cell: CellAlignLeft, Format: func(r interface{}) string { return strconv.Itoa(root(r).HierarchyID) }},
+		{Text: "CONTROLLERS", Cell: CellAlignLeft, Format: func(r interface{}) string { return root(r).Controllers }},
+		{Text: "ROOT", Cell: CellAlignLeft, Format: func(r interface{}) string { return root(r).Root }},
+		{Text: "PATH", Cell: CellAlignLeft, Format: func(r interface{}) string { return root(r).Path }},
+	}
+}
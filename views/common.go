@@ -17,6 +17,8 @@
 package views
 
 import (
+	"fmt"
+	"reflect"
 	"strings"
 
 	"github.com/gdamore/tcell/v2"
@@ -28,6 +30,60 @@ type Field struct {
 	Hide bool // default false ,show all
 
 	Cell func(text string, v interface{}) *tview.TableCell
+	// Format turn a typed row, such as modle.Process, into the text of this column
+	Format func(row interface{}) string
+}
+
+// SelectFields show only the fields named in names, case insensitive, and hide the others
+func SelectFields(fields []Field, names []string) error {
+	show := map[string]bool{}
+	for _, name := range names {
+		show[strings.ToUpper(strings.TrimSpace(name))] = true
+	}
+	for name := range show {
+		found := false
+		for _, f := range fields {
+			if strings.ToUpper(f.Text) == name {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return fmt.Errorf("unknown column %s", name)
+		}
+	}
+	for i := range fields {
+		fields[i].Hide = !show[strings.ToUpper(fields[i].Text)]
+	}
+	return nil
+}
+
+// RowsOf format items, a slice of modle types such as []modle.Route, into table data by Format of each field
+func RowsOf(fields []Field, items interface{}) [][]string {
+	return Rows(fields, Items(items))
+}
+
+// Items turn a slice of modle types into rows taken by Rows
+func Items(items interface{}) []interface{} {
+	v := reflect.ValueOf(items)
+	rows := make([]interface{}, 0, v.Len())
+	for i := 0; i < v.Len(); i++ {
+		rows = append(rows, v.Index(i).Interface())
+	}
+	return rows
+}
+
+// Rows format typed rows into table data by Format of each field
+func Rows(fields []Field, rows []interface{}) [][]string {
+	data := make([][]string, 0, len(rows))
+	for _, row := range rows {
+		r := make([]string, 0, len(fields))
+		for _, f := range fields {
+			r = append(r, f.Format(row))
+		}
+		data = append(data, r)
+	}
+	return data
 }
 
 func CellTitle(text string) *tview.TableCell {
@@ -55,10 +111,56 @@ func CellAlignCenter(text string, v interface{}) *tview.TableCell {
 		SetAlign(tview.AlignCenter).SetReference(v))
 }
 
+// CellPlain is left aligned and never highlighted, for free text such as "stp off" which is not an alert
+func CellPlain(text string, v interface{}) *tview.TableCell {
+	return tview.NewTableCell(text).
+		SetTextColor(tcell.ColorWhite).
+		SetAlign(tview.AlignLeft).SetReference(v)
+}
+
+// CellPlainRight is CellPlain aligned right
+func CellPlainRight(text string, v interface{}) *tview.TableCell {
+	return tview.NewTableCell(text).
+		SetTextColor(tcell.ColorWhite).
+		SetAlign(tview.AlignRight).SetReference(v)
+}
+
+// CellAlertNonZero is right aligned, and highlighted when value is not zero, such as drops per second
+func CellAlertNonZero(text string, v interface{}) *tview.TableCell {
+	return CellColor(tview.NewTableCell(text).
+		SetTextColor(tcell.ColorWhite).
+		SetAlign(tview.AlignRight).SetReference(Alert(text != "0" && text != "-" && text != "")))
+}
+
+// CellAlertMarked is right aligned, and highlighted when value is marked with "*"
+func CellAlertMarked(text string, v interface{}) *tview.TableCell {
+	return CellColor(tview.NewTableCell(text).
+		SetTextColor(tcell.ColorWhite).
+		SetAlign(tview.AlignRight).SetReference(Alert(strings.HasSuffix(text, "*"))))
+}
+
+// Alert as cell reference mark the cell should be highlighted
+type Alert bool
+
 func CellColor(t *tview.TableCell) *tview.TableCell {
 	if strings.Contains(t.Text, "off") ||
 		strings.Contains(t.Text, "down") {
 		t.SetTextColor(tcell.ColorRed)
 	}
+	if alert, ok := t.GetReference().(Alert); ok && bool(alert) {
+		t.SetTextColor(tcell.ColorRed)
+	}
 	return t
 }
+
+// NewModal center p above other pages, taking 80% of the screen
+func NewModal(p tview.Primitive) tview.Primitive {
+	return tview.NewFlex().
+		AddItem(nil, 0, 1, false).
+		AddItem(tview.NewFlex().
+			SetDirection(tview.FlexRow).
+			AddItem(nil, 0, 1, false).
+			AddItem(p, 0, 8, true).
+			AddItem(nil, 0, 1, false), 0, 8, true).
+		AddItem(nil, 0, 1, false)
+}
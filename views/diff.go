@@ -0,0 +1,43 @@
+/*
+ Copyright 2020  l1b0k
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package views
+
+import (
+	"github.com/l1b0k/volans/modle"
+	"github.com/rivo/tview"
+)
+
+// NewDiffView show changes since the baseline
+func NewDiffView() *tview.Table {
+	view := tview.NewTable().
+		SetBorders(false).
+		SetSelectable(true, false).
+		SetFixed(1, 0)
+	view.SetBorder(true).SetTitle("diff (Esc to close)")
+	return view
+}
+
+// DiffFields is the columns of modle.Change
+func DiffFields() []Field {
+	change := func(row interface{}) modle.Change { return row.(modle.Change) }
+	return []Field{
+		{Text: "KIND", Cell: CellAlignLeft, Format: func(r interface{}) string { return change(r).Kind }},
+		{Text: "ACTION", Cell: CellAlignLeft, Format: func(r interface{}) string { return change(r).Action }},
+		{Text: "OBJECT", Cell: CellAlignLeft, Format: func(r interface{}) string { return change(r).Object }},
+		{Text: "DETAIL", Cell: CellAlignLeft, Format: func(r interface{}) string { return change(r).Detail }},
+	}
+}
@@ -0,0 +1,74 @@
+/*
+ Copyright 2020  l1b0k
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package views
+
+import (
+	"math"
+	"strconv"
+
+	"github.com/l1b0k/volans/modle"
+	"github.com/rivo/tview"
+)
+
+// NewHistoryView show how metrics of a namespace evolved
+func NewHistoryView() *tview.Table {
+	view := tview.NewTable().
+		SetBorders(false).
+		SetSelectable(true, false).
+		SetFixed(1, 0)
+	view.SetBorder(true).SetTitle("history (Esc to close)")
+	return view
+}
+
+// HistoryFields is the columns of modle.Series
+func HistoryFields() []Field {
+	series := func(row interface{}) modle.Series { return row.(modle.Series) }
+	// stat format an aggregate of values which are not NaN
+	stat := func(fn func(values []float64) float64) func(interface{}) string {
+		return func(row interface{}) string {
+			var values []float64
+			for _, v := range series(row).Values {
+				if !math.IsNaN(v) {
+					values = append(values, v)
+				}
+			}
+			if len(values) == 0 {
+				return "-"
+			}
+			return strconv.FormatFloat(fn(values), 'f', -1, 64)
+		}
+	}
+	return []Field{
+		{Text: "SERIES", Cell: CellAlignLeft, Format: func(r interface{}) string { return series(r).Name }},
+		{Text: "MIN", Cell: CellAlignRight, Format: stat(func(v []float64) float64 {
+			m := v[0]
+			for _, x := range v {
+				m = math.Min(m, x)
+			}
+			return m
+		})},
+		{Text: "MAX", Cell: CellAlignRight, Format: stat(func(v []float64) float64 {
+			m := v[0]
+			for _, x := range v {
+				m = math.Max(m, x)
+			}
+			return m
+		})},
+		{Text: "LAST", Cell: CellAlignRight, Format: stat(func(v []float64) float64 { return v[len(v)-1] })},
+		{Text: "TREND", Cell: CellAlignLeft, Format: func(r interface{}) string { return sparkline(series(r).Values) }},
+	}
+}
@@ -0,0 +1,131 @@
+/*
+ Copyright 2020  l1b0k
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package views
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/l1b0k/volans/modle"
+	"github.com/rivo/tview"
+)
+
+// NewInterfaceInfoView show what ethtool tell about an interface
+func NewInterfaceInfoView() *tview.Table {
+	view := tview.NewTable().
+		SetBorders(false).
+		SetSelectable(true, false).
+		SetFixed(1, 0)
+	view.SetBorder(true).SetTitle("interface (Esc to close)")
+	return view
+}
+
+// InterfaceInfoRow is a line of the interface detail, Section is set on the first line of a section
+type InterfaceInfoRow struct {
+	Section string
+	Name    string
+	Value   string
+	// Rate is the per-second delta of a driver counter
+	Rate string
+}
+
+// InterfaceInfoFields is the columns of InterfaceInfoRow
+func InterfaceInfoFields() []Field {
+	row := func(r interface{}) InterfaceInfoRow { return r.(InterfaceInfoRow) }
+	return []Field{
+		{Text: "SECTION", Cell: CellAlignLeft, Format: func(r interface{}) string { return row(r).Section }},
+		{Text: "NAME", Cell: CellPlain, Format: func(r interface{}) string { return row(r).Name }},
+		{Text: "VALUE", Cell: CellPlainRight, Format: func(r interface{}) string { return row(r).Value }},
+		{Text: "/s", Cell: CellAlignRight, Format: func(r interface{}) string { return row(r).Rate }},
+	}
+}
+
+// InterfaceInfoRows flatten info into sections of lines
+func InterfaceInfoRows(info *modle.InterfaceInfo) []InterfaceInfoRow {
+	var rows []InterfaceInfoRow
+	add := func(section string, lines []InterfaceInfoRow) {
+		if len(lines) == 0 {
+			lines = []InterfaceInfoRow{{Value: "-"}}
+		}
+		lines[0].Section = section
+		rows = append(rows, lines...)
+	}
+
+	add("driver", []InterfaceInfoRow{
+		{Name: "driver", Value: info.Driver},
+		{Name: "version", Value: info.Version},
+		{Name: "firmware", Value: info.Firmware},
+		{Name: "bus-info", Value: info.BusInfo},
+	})
+
+	speed := "unknown"
+	if info.Speed >= 0 {
+		speed = fmt.Sprintf("%dMb/s", info.Speed)
+	}
+	add("link", []InterfaceInfoRow{
+		{Name: "speed", Value: speed},
+		{Name: "duplex", Value: info.Duplex},
+		{Name: "autoneg", Value: onOff(info.Autoneg)},
+	})
+
+	var lines []InterfaceInfoRow
+	if r := info.Ring; r != nil {
+		ring := func(cur, max uint32) string { return fmt.Sprintf("%d/%d", cur, max) }
+		lines = []InterfaceInfoRow{
+			{Name: "rx", Value: ring(r.Rx, r.RxMax)},
+			{Name: "rx-mini", Value: ring(r.RxMini, r.RxMiniMax)},
+			{Name: "rx-jumbo", Value: ring(r.RxJumbo, r.RxJumboMax)},
+			{Name: "tx", Value: ring(r.Tx, r.TxMax)},
+		}
+	}
+	add("ring", lines)
+
+	lines = nil
+	if c := info.Coalesce; c != nil {
+		lines = []InterfaceInfoRow{
+			{Name: "adaptive-rx", Value: onOff(c.AdaptiveRx)},
+			{Name: "adaptive-tx", Value: onOff(c.AdaptiveTx)},
+		}
+		for _, kv := range c.Values {
+			lines = append(lines, InterfaceInfoRow{Name: kv.Key, Value: kv.Value})
+		}
+	}
+	add("coalesce", lines)
+
+	lines = nil
+	for _, f := range info.Features {
+		value := onOff(f.Active)
+		if f.Fixed {
+			value += " [fixed]"
+		} else if f.Requested != f.Active {
+			value += " [requested " + onOff(f.Requested) + "]"
+		}
+		lines = append(lines, InterfaceInfoRow{Name: f.Name, Value: value})
+	}
+	add("features", lines)
+
+	lines = nil
+	for _, s := range info.Stats {
+		rate := "-"
+		if s.Rate != nil {
+			rate = formatCount(*s.Rate)
+		}
+		lines = append(lines, InterfaceInfoRow{Name: s.Name, Value: strconv.FormatUint(s.Value, 10), Rate: rate})
+	}
+	add("stats", lines)
+	return rows
+}
@@ -0,0 +1,54 @@
+/*
+ Copyright 2020  l1b0k
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package views
+
+import (
+	"strconv"
+
+	"github.com/l1b0k/volans/modle"
+	"github.com/rivo/tview"
+)
+
+// NewIPCView show SysV IPC objects of an ipc namespace
+func NewIPCView() *tview.Table {
+	view := tview.NewTable().
+		SetBorders(false).
+		SetSelectable(false, false).
+		SetFixed(1, 0)
+	view.SetBorder(true).SetTitle("ipc")
+	return view
+}
+
+// IPCFields is the columns of modle.IPCObject
+func IPCFields() []Field {
+	obj := func(row interface{}) modle.IPCObject { return row.(modle.IPCObject) }
+	return []Field{
+		{Text: "TYPE", Cell: CellAlignLeft, Format: func(r interface{}) string { return obj(r).Type }},
+		{Text: "KEY", Cell: CellAlignRight, Format: func(r interface{}) string { return obj(r).Key }},
+		{Text: "ID", Cell: CellAlignRight, Format: func(r interface{}) string { return strconv.Itoa(obj(r).ID) }},
+		{Text: "PERMS", Cell: CellAlignRight, Format: func(r interface{}) string { return obj(r).Perms }},
+		{Text: "UID", Cell: CellAlignRight, Format: func(r interface{}) string { return strconv.Itoa(obj(r).UID) }},
+		{Text: "GID", Cell: CellAlignRight, Format: func(r interface{}) string { return strconv.Itoa(obj(r).GID) }},
+		{Text: "SIZE", Cell: CellAlignRight, Format: func(r interface{}) string { return strconv.FormatUint(obj(r).Size, 10) }},
+		{Text: "NATTCH/QNUM", Cell: CellAlignRight, Format: func(r interface{}) string {
+			if obj(r).Count == nil {
+				return ""
+			}
+			return strconv.FormatUint(*obj(r).Count, 10)
+		}},
+	}
+}
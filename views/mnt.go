@@ -0,0 +1,48 @@
+/*
+ Copyright 2020  l1b0k
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package views
+
+import (
+	"strconv"
+
+	"github.com/l1b0k/volans/modle"
+	"github.com/rivo/tview"
+)
+
+// NewMntView show mounts of a mnt namespace
+func NewMntView() *tview.Table {
+	view := tview.NewTable().
+		SetBorders(false).
+		SetSelectable(false, false).
+		SetFixed(1, 0)
+	view.SetBorder(true).SetTitle("mnt")
+	return view
+}
+
+// MntFields is the columns of modle.Mount
+func MntFields() []Field {
+	mount := func(row interface{}) modle.Mount { return row.(modle.Mount) }
+	return []Field{
+		{Text: "ID", Cell: CellAlignLeft, Format: func(r interface{}) string { return strconv.Itoa(mount(r).ID) }},
+		{Text: "PARENT", Cell: CellAlignRight, Format: func(r interface{}) string { return strconv.Itoa(mount(r).Parent) }},
+		{Text: "SOURCE", Cell: CellAlignLeft, Format: func(r interface{}) string { return mount(r).Source }},
+		{Text: "TARGET", Cell: CellAlignLeft, Format: func(r interface{}) string { return mount(r).Target }},
+		{Text: "FSTYPE", Cell: CellAlignRight, Format: func(r interface{}) string { return mount(r).FSType }},
+		{Text: "OPTIONS", Cell: CellAlignLeft, Format: func(r interface{}) string { return mount(r).Options }},
+		{Text: "PROPAGATION", Cell: CellAlignLeft, Format: func(r interface{}) string { return mount(r).Propagation }},
+	}
+}
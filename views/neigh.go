@@ -0,0 +1,45 @@
+/*
+ Copyright 2020  l1b0k
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package views
+
+import (
+	"strings"
+
+	"github.com/l1b0k/volans/modle"
+	"github.com/rivo/tview"
+)
+
+// NewNeighView show neighbor table of a net namespace
+func NewNeighView() *tview.Table {
+	view := tview.NewTable().
+		SetBorders(false).
+		SetSelectable(false, false).
+		SetFixed(1, 0)
+	view.SetBorder(true).SetTitle("neigh")
+	return view
+}
+
+// NeighFields is the columns of modle.Neigh
+func NeighFields() []Field {
+	neigh := func(row interface{}) modle.Neigh { return row.(modle.Neigh) }
+	return []Field{
+		{Text: "IP", Cell: CellAlignLeft, Format: func(r interface{}) string { return neigh(r).IP }},
+		{Text: "LLADDR", Cell: CellAlignRight, Format: func(r interface{}) string { return neigh(r).LLAddr }},
+		{Text: "DEV", Cell: CellAlignRight, Format: func(r interface{}) string { return neigh(r).Dev }},
+		{Text: "STATE", Cell: CellAlignRight, Format: func(r interface{}) string { return strings.Join(neigh(r).State, ",") }},
+	}
+}
@@ -1,6 +1,11 @@
 package views
 
 import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/l1b0k/volans/modle"
 	"github.com/rivo/tview"
 )
 
@@ -13,3 +18,61 @@ func NewNetNSView() *tview.Table {
 	view.SetBorder(true).SetTitle("net")
 	return view
 }
+
+// NetNSFields is the columns of modle.Interface
+func NetNSFields() []Field {
+	iface := func(row interface{}) modle.Interface { return row.(modle.Interface) }
+	// rate format a per-second rate, "-" before the second sample
+	rate := func(v func(r *modle.NetRate) float64, format func(float64) string) func(interface{}) string {
+		return func(row interface{}) string {
+			r := iface(row).Rate
+			if r == nil {
+				return "-"
+			}
+			return format(v(r))
+		}
+	}
+	return []Field{
+		{Text: "IF", Cell: CellAlignLeft, Format: func(r interface{}) string { return iface(r).Name }},
+		{Text: "Type", Cell: CellAlignRight, Format: func(r interface{}) string { return iface(r).Type }},
+		{Text: "MAC", Cell: CellAlignRight, Format: func(r interface{}) string { return iface(r).MAC }},
+		{Text: "CH", Cell: CellAlignRight, Format: func(r interface{}) string {
+			ch := iface(r).Channels
+			if ch == nil {
+				return ""
+			}
+			return fmt.Sprintf("%d/%d", ch.Combined, ch.MaxCombined)
+		}},
+		{Text: "IP", Cell: CellAlignRight, Format: func(r interface{}) string { return strings.Join(iface(r).IPs, ",") }},
+		{Text: "PEER", Cell: CellAlignRight, Format: func(r interface{}) string {
+			p := iface(r).Peer
+			if p == nil {
+				return ""
+			}
+			return p.NS + "/" + p.Name
+		}},
+		{Text: "DETAILS", Cell: CellPlain, Format: func(r interface{}) string { return strings.Join(iface(r).Details, ", ") }},
+		{Text: "rx/s", Cell: CellAlignRight, Format: rate(func(r *modle.NetRate) float64 { return r.RxBytes }, formatBytes)},
+		{Text: "tx/s", Cell: CellAlignRight, Format: rate(func(r *modle.NetRate) float64 { return r.TxBytes }, formatBytes)},
+		{Text: "rxPkt/s", Cell: CellAlignRight, Format: rate(func(r *modle.NetRate) float64 { return r.RxPackets }, formatCount)},
+		{Text: "txPkt/s", Cell: CellAlignRight, Format: rate(func(r *modle.NetRate) float64 { return r.TxPackets }, formatCount)},
+		{Text: "rxErr/s", Cell: CellAlertNonZero, Format: rate(func(r *modle.NetRate) float64 { return r.RxErrs }, formatCount)},
+		{Text: "rxDrop/s", Cell: CellAlertNonZero, Format: rate(func(r *modle.NetRate) float64 { return r.RxDrop }, formatCount)},
+		{Text: "txErr/s", Cell: CellAlertNonZero, Format: rate(func(r *modle.NetRate) float64 { return r.TxErrs }, formatCount)},
+		{Text: "txDrop/s", Cell: CellAlertNonZero, Format: rate(func(r *modle.NetRate) float64 { return r.TxDrop }, formatCount)},
+		{Text: "rxErr", Cell: CellAlignRight, Format: func(r interface{}) string { return strconv.FormatUint(iface(r).RxErrs, 10) }},
+		{Text: "rxDrop", Cell: CellAlignRight, Format: func(r interface{}) string { return strconv.FormatUint(iface(r).RxDrop, 10) }},
+		{Text: "txErr", Cell: CellAlignRight, Format: func(r interface{}) string { return strconv.FormatUint(iface(r).TxErrs, 10) }},
+		{Text: "txDrop", Cell: CellAlignRight, Format: func(r interface{}) string { return strconv.FormatUint(iface(r).TxDrop, 10) }},
+		{Text: "MTU", Cell: CellAlignRight, Format: func(r interface{}) string { return strconv.Itoa(iface(r).MTU) }},
+		{Text: "Flag", Cell: CellAlignRight, Format: func(r interface{}) string { return strings.Join(iface(r).Flags, "|") }},
+		{Text: "GSO", Cell: CellAlignRight, Format: func(r interface{}) string { return onOff(iface(r).GSO) }},
+		{Text: "TSO", Cell: CellAlignRight, Format: func(r interface{}) string { return onOff(iface(r).TSO) }},
+		{Text: "LRO", Cell: CellAlignRight, Format: func(r interface{}) string { return onOff(iface(r).LRO) }},
+		{Text: "GRO", Cell: CellAlignRight, Format: func(r interface{}) string { return onOff(iface(r).GRO) }},
+		{Text: "SG", Cell: CellAlignRight, Format: func(r interface{}) string { return onOff(iface(r).SG) }},
+		{Text: "CSUM[rx/tx]", Cell: CellAlignRight, Format: func(r interface{}) string {
+			return fmt.Sprintf("%s/%s", onOff(iface(r).RxChecksum), onOff(iface(r).TxChecksum))
+		}},
+	}
+}
@@ -17,6 +17,10 @@
 package views
 
 import (
+	"strconv"
+	"strings"
+
+	"github.com/l1b0k/volans/modle"
 	"github.com/rivo/tview"
 )
 
@@ -29,3 +33,15 @@ func NewNSView() *tview.Table {
 	view.SetBorder(true).SetTitle("ns")
 	return view
 }
+
+// NSFields is the columns of modle.Namespace
+func NSFields() []Field {
+	ns := func(row interface{}) modle.Namespace { return row.(modle.Namespace) }
+	return []Field{
+		{Text: "NS", Cell: CellAlignLeft, Format: func(r interface{}) string { return ns(r).Inode }},
+		{Text: "TYPE", Cell: CellAlignRight, Format: func(r interface{}) string { return ns(r).Type }},
+		{Text: "NPROCS", Cell: CellAlignRight, Format: func(r interface{}) string { return strconv.Itoa(ns(r).NProcs) }},
+		{Text: "POD", Cell: CellAlignRight, Format: func(r interface{}) string { return strings.Join(ns(r).Pods, ",") }},
+		{Text: "CONTAINERS", Cell: CellAlignLeft, Format: func(r interface{}) string { return strings.Join(ns(r).Containers, ",") }},
+	}
+}
@@ -17,6 +17,13 @@
 package views
 
 import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/l1b0k/volans/modle"
 	"github.com/rivo/tview"
 )
 
@@ -29,3 +36,155 @@ func NewProcView() *tview.Table {
 	view.SetBorder(true).SetTitle("proc")
 	return view
 }
+
+// ProcNode is a process placed in the process tree
+type ProcNode struct {
+	modle.Process
+	Depth int
+	// HasChildren is true if any process in the tree has this one as parent
+	HasChildren bool
+	Collapsed   bool
+	// ForeignParent is true if the parent lives in another namespace
+	ForeignParent bool
+}
+
+// ProcTree order procs as a tree built from PPid, children are placed under their parent ordered by pid.
+// Processes whose parent is not in procs are roots. Children of a pid in collapsed are left out.
+func ProcTree(procs []modle.Process, collapsed map[int]bool) []ProcNode {
+	byPid := make(map[int]bool, len(procs))
+	for _, p := range procs {
+		byPid[p.Pid] = true
+	}
+	children := map[int][]modle.Process{}
+	var roots []modle.Process
+	for _, p := range procs {
+		if p.PPid != p.Pid && byPid[p.PPid] {
+			children[p.PPid] = append(children[p.PPid], p)
+		} else {
+			roots = append(roots, p)
+		}
+	}
+	byPidOrder := func(ps []modle.Process) {
+		sort.Slice(ps, func(i, j int) bool { return ps[i].Pid < ps[j].Pid })
+	}
+
+	var nodes []ProcNode
+	var walk func(p modle.Process, depth int)
+	walk = func(p modle.Process, depth int) {
+		node := ProcNode{
+			Process:       p,
+			Depth:         depth,
+			HasChildren:   len(children[p.Pid]) > 0,
+			Collapsed:     collapsed[p.Pid],
+			ForeignParent: depth == 0 && p.PPid != 0,
+		}
+		nodes = append(nodes, node)
+		if node.Collapsed {
+			return
+		}
+		byPidOrder(children[p.Pid])
+		for _, c := range children[p.Pid] {
+			walk(c, depth+1)
+		}
+	}
+	byPidOrder(roots)
+	for _, p := range roots {
+		walk(p, 0)
+	}
+	return nodes
+}
+
+// ProcFields is the columns of modle.Process, or ProcNode to show the tree in Name
+func ProcFields() []Field {
+	proc := func(row interface{}) modle.Process {
+		if n, ok := row.(ProcNode); ok {
+			return n.Process
+		}
+		return row.(modle.Process)
+	}
+	return []Field{
+		{Text: "PID", Cell: CellAlignLeft, Format: func(r interface{}) string { return strconv.Itoa(proc(r).Pid) }},
+		{Text: "PPID", Cell: CellAlignRight, Format: func(r interface{}) string { return strconv.Itoa(proc(r).PPid) }},
+		{Text: "Name", Cell: CellAlignLeft, Format: func(r interface{}) string {
+			n, ok := r.(ProcNode)
+			if !ok {
+				return proc(r).Name
+			}
+			return treePrefix(n) + n.Name
+		}},
+		{Text: "USER", Cell: CellAlignRight, Format: func(r interface{}) string { return proc(r).User }},
+		{Text: "S", Cell: CellAlignRight, Format: func(r interface{}) string { return proc(r).State }},
+		{Text: "CPU%", Cell: CellAlignRight, Format: func(r interface{}) string {
+			p := proc(r).CPUPercent
+			if p == nil {
+				return "-"
+			}
+			return fmt.Sprintf("%.1f", *p)
+		}},
+		{Text: "RSS", Cell: CellAlignRight, Format: func(r interface{}) string { return formatBytes(float64(proc(r).RSS)) }},
+		{Text: "VSZ", Cell: CellAlignRight, Hide: true, Format: func(r interface{}) string { return formatBytes(float64(proc(r).VSZ)) }},
+		{Text: "THR", Cell: CellAlignRight, Format: func(r interface{}) string { return strconv.Itoa(proc(r).Threads) }},
+		{Text: "FD", Cell: CellAlignRight, Format: func(r interface{}) string {
+			if proc(r).FDs < 0 {
+				return "-"
+			}
+			return strconv.Itoa(proc(r).FDs)
+		}},
+		{Text: "NI", Cell: CellAlignRight, Format: func(r interface{}) string { return strconv.Itoa(proc(r).Nice) }},
+		{Text: "PRI", Cell: CellAlignRight, Hide: true, Format: func(r interface{}) string { return strconv.Itoa(proc(r).Priority) }},
+		{Text: "START", Cell: CellAlignRight, Format: func(r interface{}) string { return formatStart(proc(r).StartedAt, time.Now()) }},
+		{Text: "CPUS", Cell: CellAlignRight, Format: func(r interface{}) string { return orDash(proc(r).CpusAllowedList) }},
+		{Text: "MEMS", Cell: CellAlignRight, Format: func(r interface{}) string { return orDash(proc(r).MemsAllowedList) }},
+		{Text: "LAST", Cell: CellAlignRight, Format: func(r interface{}) string { return strconv.Itoa(proc(r).LastCPU) }},
+		{Text: "NODE", Cell: CellAlertMarked, Format: func(r interface{}) string { return formatNode(proc(r)) }},
+		{Text: "POD", Cell: CellAlignRight, Format: func(r interface{}) string { return proc(r).Pod }},
+		{Text: "CONTAINER", Cell: CellAlignRight, Format: func(r interface{}) string { return proc(r).Container }},
+		{Text: "CMD", Cell: CellAlignLeft, Format: func(r interface{}) string { return truncate(proc(r).Cmdline, 20) }},
+		{Text: "CMDLINE", Cell: CellAlignLeft, Hide: true, Format: func(r interface{}) string { return proc(r).Cmdline }},
+	}
+}
+
+// orDash print "-" for an empty value
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
+
+// formatNode print the NUMA node of the last cpu, marked with "*" when
+// the node is not in the memory nodes the process is allowed to use
+func formatNode(p modle.Process) string {
+	if p.NUMANode < 0 {
+		return "-"
+	}
+	node := strconv.Itoa(p.NUMANode)
+	mems, err := modle.ParseCPUList(p.MemsAllowedList)
+	if err != nil || len(mems) == 0 {
+		return node
+	}
+	for _, m := range mems {
+		if m == p.NUMANode {
+			return node
+		}
+	}
+	return node + "*"
+}
+
+// treePrefix indent a node by depth, and mark it with
+// "+" collapsed, "-" expanded, "^" parent in another namespace
+func treePrefix(n ProcNode) string {
+	mark := " "
+	switch {
+	case n.HasChildren && n.Collapsed:
+		mark = "+"
+	case n.HasChildren:
+		mark = "-"
+	}
+	if n.ForeignParent {
+		mark += "^"
+	} else {
+		mark += " "
+	}
+	return strings.Repeat("  ", n.Depth) + mark
+}
@@ -0,0 +1,123 @@
+/*
+ Copyright 2020  l1b0k
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package views
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/l1b0k/volans/modle"
+	"github.com/rivo/tview"
+)
+
+// redacted replace the values of environ unless revealed
+const redacted = "***"
+
+// NewProcInfoView show the detail of a process
+func NewProcInfoView() *tview.Table {
+	view := tview.NewTable().
+		SetBorders(false).
+		SetSelectable(true, false).
+		SetFixed(1, 0)
+	view.SetBorder(true).SetTitle("process (Esc to close)")
+	return view
+}
+
+// ProcInfoRow is a line of the process detail, Section is set on the first line of a section
+type ProcInfoRow struct {
+	Section string
+	Name    string
+	Value   string
+}
+
+// ProcInfoFields is the columns of ProcInfoRow
+func ProcInfoFields() []Field {
+	row := func(r interface{}) ProcInfoRow { return r.(ProcInfoRow) }
+	return []Field{
+		{Text: "SECTION", Cell: CellAlignLeft, Format: func(r interface{}) string { return row(r).Section }},
+		{Text: "NAME", Cell: CellPlain, Format: func(r interface{}) string { return row(r).Name }},
+		{Text: "VALUE", Cell: CellPlain, Format: func(r interface{}) string { return row(r).Value }},
+	}
+}
+
+// ProcInfoRows flatten info into sections of lines, values of environ are hidden when redact
+func ProcInfoRows(info *modle.ProcInfo, redact bool) []ProcInfoRow {
+	var rows []ProcInfoRow
+	add := func(section string, lines []ProcInfoRow) {
+		if len(lines) == 0 {
+			lines = []ProcInfoRow{{Value: "-"}}
+		}
+		lines[0].Section = section
+		rows = append(rows, lines...)
+	}
+
+	var lines []ProcInfoRow
+	for _, kv := range info.Status {
+		lines = append(lines, ProcInfoRow{Name: kv.Key, Value: kv.Value})
+	}
+	add("status", lines)
+
+	lines = nil
+	for _, l := range info.Limits {
+		lines = append(lines, ProcInfoRow{Name: l.Name, Value: strings.TrimSpace(l.Soft + " / " + l.Hard + " " + l.Units)})
+	}
+	add("limits", lines)
+
+	lines = nil
+	for _, e := range info.Environ {
+		parts := strings.SplitN(e, "=", 2)
+		value := ""
+		if len(parts) == 2 {
+			value = parts[1]
+		}
+		if redact && value != "" {
+			value = redacted
+		}
+		lines = append(lines, ProcInfoRow{Name: parts[0], Value: value})
+	}
+	add("environ", lines)
+
+	add("links", []ProcInfoRow{
+		{Name: "cwd", Value: info.Cwd},
+		{Name: "exe", Value: info.Exe},
+		{Name: "root", Value: info.Root},
+	})
+
+	lines = nil
+	for _, fd := range info.FDs {
+		value := fd.Target
+		if fd.Detail != "" {
+			value = fd.Detail
+		}
+		lines = append(lines, ProcInfoRow{Name: strconv.Itoa(fd.FD), Value: fd.Kind + " " + value})
+	}
+	add("fds", lines)
+
+	lines = nil
+	for _, c := range info.Capabilities {
+		value := strings.Join(c.Names, ",")
+		switch {
+		case c.Mask == 0:
+			value = "none"
+		case modle.FullCapabilities(c.Mask):
+			value = "full"
+		}
+		lines = append(lines, ProcInfoRow{Name: c.Name, Value: value})
+	}
+	add("capabilities", lines)
+	return rows
+}
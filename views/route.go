@@ -0,0 +1,51 @@
+/*
+ Copyright 2020  l1b0k
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package views
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/l1b0k/volans/modle"
+	"github.com/rivo/tview"
+)
+
+// NewRouteView show routes of all tables in a net namespace
+func NewRouteView() *tview.Table {
+	view := tview.NewTable().
+		SetBorders(false).
+		SetSelectable(false, false).
+		SetFixed(1, 0)
+	view.SetBorder(true).SetTitle("routes")
+	return view
+}
+
+// RouteFields is the columns of modle.Route
+func RouteFields() []Field {
+	route := func(row interface{}) modle.Route { return row.(modle.Route) }
+	return []Field{
+		{Text: "TABLE", Cell: CellAlignLeft, Format: func(r interface{}) string { return route(r).Table }},
+		{Text: "DST", Cell: CellAlignLeft, Format: func(r interface{}) string { return route(r).Dst }},
+		{Text: "GW", Cell: CellAlignLeft, Format: func(r interface{}) string { return strings.Join(route(r).Gateways, ",") }},
+		{Text: "DEV", Cell: CellAlignRight, Format: func(r interface{}) string { return strings.Join(route(r).Devs, ",") }},
+		{Text: "SRC", Cell: CellAlignRight, Format: func(r interface{}) string { return route(r).Src }},
+		{Text: "PROTO", Cell: CellAlignRight, Format: func(r interface{}) string { return route(r).Protocol }},
+		{Text: "SCOPE", Cell: CellAlignRight, Format: func(r interface{}) string { return route(r).Scope }},
+		{Text: "TYPE", Cell: CellAlignRight, Format: func(r interface{}) string { return route(r).Type }},
+		{Text: "METRIC", Cell: CellAlignRight, Format: func(r interface{}) string { return strconv.Itoa(route(r).Metric) }},
+	}
+}
@@ -0,0 +1,49 @@
+/*
+ Copyright 2020  l1b0k
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package views
+
+import (
+	"strconv"
+
+	"github.com/l1b0k/volans/modle"
+	"github.com/rivo/tview"
+)
+
+// NewRuleView show policy routing rules of a net namespace
+func NewRuleView() *tview.Table {
+	view := tview.NewTable().
+		SetBorders(false).
+		SetSelectable(false, false).
+		SetFixed(1, 0)
+	view.SetBorder(true).SetTitle("rules")
+	return view
+}
+
+// RuleFields is the columns of modle.Rule
+func RuleFields() []Field {
+	rule := func(row interface{}) modle.Rule { return row.(modle.Rule) }
+	return []Field{
+		{Text: "FAMILY", Cell: CellAlignLeft, Format: func(r interface{}) string { return rule(r).Family }},
+		{Text: "PRIO", Cell: CellAlignRight, Format: func(r interface{}) string { return strconv.Itoa(rule(r).Priority) }},
+		{Text: "FROM", Cell: CellAlignLeft, Format: func(r interface{}) string { return rule(r).From }},
+		{Text: "TO", Cell: CellAlignLeft, Format: func(r interface{}) string { return rule(r).To }},
+		{Text: "IIF", Cell: CellAlignRight, Format: func(r interface{}) string { return rule(r).IIF }},
+		{Text: "OIF", Cell: CellAlignRight, Format: func(r interface{}) string { return rule(r).OIF }},
+		{Text: "FWMARK", Cell: CellAlignRight, Format: func(r interface{}) string { return rule(r).FWMark }},
+		{Text: "TABLE", Cell: CellAlignRight, Format: func(r interface{}) string { return rule(r).Table }},
+	}
+}
@@ -0,0 +1,48 @@
+/*
+ Copyright 2020  l1b0k
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package views
+
+import (
+	"strconv"
+
+	"github.com/l1b0k/volans/modle"
+	"github.com/rivo/tview"
+)
+
+// NewSocketView show tcp, udp and unix sockets of a net namespace
+func NewSocketView() *tview.Table {
+	view := tview.NewTable().
+		SetBorders(false).
+		SetSelectable(false, false).
+		SetFixed(1, 0)
+	view.SetBorder(true).SetTitle("sockets")
+	return view
+}
+
+// SocketFields is the columns of modle.Socket
+func SocketFields() []Field {
+	socket := func(row interface{}) modle.Socket { return row.(modle.Socket) }
+	return []Field{
+		{Text: "PROTO", Cell: CellAlignLeft, Format: func(r interface{}) string { return socket(r).Proto }},
+		{Text: "LOCAL", Cell: CellAlignLeft, Format: func(r interface{}) string { return socket(r).Local }},
+		{Text: "REMOTE", Cell: CellAlignLeft, Format: func(r interface{}) string { return socket(r).Remote }},
+		{Text: "STATE", Cell: CellAlignRight, Format: func(r interface{}) string { return socket(r).State }},
+		{Text: "RECV-Q", Cell: CellAlignRight, Format: func(r interface{}) string { return strconv.FormatUint(uint64(socket(r).RecvQ), 10) }},
+		{Text: "SEND-Q", Cell: CellAlignRight, Format: func(r interface{}) string { return strconv.FormatUint(uint64(socket(r).SendQ), 10) }},
+		{Text: "PID", Cell: CellAlignLeft, Format: func(r interface{}) string { return joinInts(socket(r).PIDs) }},
+	}
+}
@@ -0,0 +1,35 @@
+/*
+ Copyright 2020  l1b0k
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package views
+
+import (
+	"fmt"
+
+	"github.com/rivo/tview"
+)
+
+// NewTabBar show tab names, the selected one is highlighted by its region
+func NewTabBar(names []string) *tview.TextView {
+	view := tview.NewTextView().
+		SetDynamicColors(true).
+		SetRegions(true).
+		SetWrap(false)
+	for i, name := range names {
+		fmt.Fprintf(view, `%d ["%d"][darkcyan]%s[white][""]  `, i+1, i, name)
+	}
+	return view
+}
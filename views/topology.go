@@ -0,0 +1,136 @@
+/*
+ Copyright 2020  l1b0k
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package views
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/l1b0k/volans/modle"
+	"github.com/mattn/go-runewidth"
+	"github.com/rivo/tview"
+)
+
+// NewTopologyView show how net namespaces connect to each other
+func NewTopologyView() *tview.TextView {
+	view := tview.NewTextView().
+		SetDynamicColors(true).
+		SetWrap(false)
+	view.SetBorder(true).SetTitle("topology (Esc to close)")
+	return view
+}
+
+// Topology draw each net namespace as a box of its links, bridge and bond ports are nested under
+// their master, and the edge of a link is drawn after it:
+// "<->" veth peer, "^" lower device of vlan, ipvlan, macvlan or vxlan, "~>" far end of a tunnel
+func Topology(nodes []modle.TopologyNode) string {
+	pods := map[string]string{}
+	for _, n := range nodes {
+		pods[n.NS] = strings.Join(n.Pods, ",")
+	}
+	ref := func(p *modle.Peer) string {
+		s := p.NS + "/" + p.Name
+		if pods[p.NS] != "" {
+			s += " (" + pods[p.NS] + ")"
+		}
+		return s
+	}
+
+	var b strings.Builder
+	b.WriteString("[darkcyan]<->[white] veth peer  [darkcyan]^[white] lower device  [darkcyan]~>[white] tunnel\n\n")
+	for _, n := range nodes {
+		title := "net " + n.NS
+		if pods[n.NS] != "" {
+			title += "  " + pods[n.NS]
+		}
+		fmt.Fprintf(&b, "┌─ [yellow]%s[white]\n", tview.Escape(title))
+
+		names := map[string]bool{}
+		ports := map[string][]modle.TopologyLink{}
+		for _, l := range n.Links {
+			names[l.Name] = true
+			if l.Master != "" {
+				ports[l.Master] = append(ports[l.Master], l)
+			}
+		}
+		// walk links from those without master down through ports at any depth,
+		// e.g. a vlan on a bond which is a bridge port
+		type entry struct {
+			name string
+			link modle.TopologyLink
+		}
+		var entries []entry
+		seen := map[string]bool{}
+		var walk func(prefix, branch string, l modle.TopologyLink)
+		walk = func(prefix, branch string, l modle.TopologyLink) {
+			if seen[l.Name] {
+				return
+			}
+			seen[l.Name] = true
+			entries = append(entries, entry{prefix + branch + l.Name, l})
+			switch branch {
+			case "├─ ":
+				prefix += "│  "
+			case "└─ ":
+				prefix += "   "
+			}
+			for i, p := range ports[l.Name] {
+				if i == len(ports[l.Name])-1 {
+					walk(prefix, "└─ ", p)
+				} else {
+					walk(prefix, "├─ ", p)
+				}
+			}
+		}
+		for _, l := range n.Links {
+			// a master not in the namespace leave its port at top
+			if (l.Master != "" && names[l.Master]) || l.Name == "lo" {
+				continue
+			}
+			walk("", "", l)
+		}
+
+		width := 0
+		for _, e := range entries {
+			if w := runewidth.StringWidth(e.name); w > width {
+				width = w
+			}
+		}
+		for _, e := range entries {
+			l := e.link
+			state := ""
+			if !l.Up {
+				state = " [red]down[white]"
+			}
+			var edges []string
+			if l.Peer != nil {
+				edges = append(edges, "[darkcyan]<->[white] "+tview.Escape(ref(l.Peer)))
+			}
+			if l.Parent != nil {
+				edges = append(edges, "[darkcyan]^[white] "+tview.Escape(ref(l.Parent)))
+			}
+			if l.Tunnel != "" {
+				edges = append(edges, "[darkcyan]~>[white] "+tview.Escape(l.Tunnel))
+			}
+			// box drawing runes take one column but several bytes, so pad by display width
+			pad := strings.Repeat(" ", width-runewidth.StringWidth(e.name))
+			fmt.Fprintf(&b, "│ %s%s %-8s%s %s\n", tview.Escape(e.name), pad, l.Type, state, strings.Join(edges, "  "))
+		}
+		b.WriteString("└─\n")
+	}
+	return b.String()
+}
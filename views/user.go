@@ -0,0 +1,45 @@
+/*
+ Copyright 2020  l1b0k
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package views
+
+import (
+	"strconv"
+
+	"github.com/l1b0k/volans/modle"
+	"github.com/rivo/tview"
+)
+
+// NewUserView show uid/gid mapping of a user namespace
+func NewUserView() *tview.Table {
+	view := tview.NewTable().
+		SetBorders(false).
+		SetSelectable(false, false).
+		SetFixed(1, 0)
+	view.SetBorder(true).SetTitle("user")
+	return view
+}
+
+// UserFields is the columns of modle.IDMap
+func UserFields() []Field {
+	idmap := func(row interface{}) modle.IDMap { return row.(modle.IDMap) }
+	return []Field{
+		{Text: "MAP", Cell: CellAlignLeft, Format: func(r interface{}) string { return idmap(r).Map }},
+		{Text: "INSIDE", Cell: CellAlignRight, Format: func(r interface{}) string { return strconv.FormatUint(uint64(idmap(r).Inside), 10) }},
+		{Text: "OUTSIDE", Cell: CellAlignRight, Format: func(r interface{}) string { return strconv.FormatUint(uint64(idmap(r).Outside), 10) }},
+		{Text: "RANGE", Cell: CellAlignRight, Format: func(r interface{}) string { return strconv.FormatUint(uint64(idmap(r).Range), 10) }},
+	}
+}
@@ -0,0 +1,41 @@
+/*
+ Copyright 2020  l1b0k
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package views
+
+import (
+	"github.com/l1b0k/volans/modle"
+	"github.com/rivo/tview"
+)
+
+// NewUTSView show hostname and domainname of a uts namespace
+func NewUTSView() *tview.Table {
+	view := tview.NewTable().
+		SetBorders(false).
+		SetSelectable(false, false).
+		SetFixed(1, 0)
+	view.SetBorder(true).SetTitle("uts")
+	return view
+}
+
+// UTSFields is the columns of modle.KeyValue
+func UTSFields() []Field {
+	kv := func(row interface{}) modle.KeyValue { return row.(modle.KeyValue) }
+	return []Field{
+		{Text: "KEY", Cell: CellAlignLeft, Format: func(r interface{}) string { return kv(r).Key }},
+		{Text: "VALUE", Cell: CellAlignLeft, Format: func(r interface{}) string { return kv(r).Value }},
+	}
+}